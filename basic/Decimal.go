@@ -0,0 +1,207 @@
+package basic
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/reddyvinod/partialencode/jlexer"
+	"github.com/reddyvinod/partialencode/jwriter"
+)
+
+// MaxDecimalScale is the largest absolute scale of a DecimalValue accepted by ParseDecimal.
+const MaxDecimalScale = 64
+
+var (
+	errDecimalSyntax   = errors.New("invalid decimal")
+	errDecimalOverflow = errors.New("decimal out of range")
+)
+
+// DecimalValue is a fixed-point decimal number equal to Coef * 10^-Scale. The scale of parsed
+// values is kept, so 1.50 is stored as {150, 2} and formatted as 1.50 again.
+type DecimalValue struct {
+	Coef  int64
+	Scale int32
+}
+
+// ParseDecimal parses a decimal number in JSON number syntax without rounding. An error is
+// returned if the coefficient does not fit into int64 or the scale exceeds MaxDecimalScale.
+func ParseDecimal(s string) (DecimalValue, error) {
+	var (
+		coef   uint64
+		scale  int64
+		digits int
+		i      int
+	)
+
+	neg := len(s) > 0 && s[0] == '-'
+	if neg {
+		i++
+	}
+	for point := false; i < len(s); i++ {
+		c := s[i]
+		if c == '.' && !point {
+			point = true
+			continue
+		}
+		if c < '0' || c > '9' {
+			break
+		}
+		if coef > (1<<63-1-uint64(c-'0'))/10 {
+			return DecimalValue{}, errDecimalOverflow
+		}
+		coef = coef*10 + uint64(c-'0')
+		digits++
+		if point {
+			scale++
+		}
+	}
+	if digits == 0 {
+		return DecimalValue{}, errDecimalSyntax
+	}
+
+	if i < len(s) {
+		if s[i] != 'e' && s[i] != 'E' {
+			return DecimalValue{}, errDecimalSyntax
+		}
+		exp, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return DecimalValue{}, errDecimalSyntax
+		}
+		scale -= exp
+	}
+	if scale > MaxDecimalScale || scale < -MaxDecimalScale {
+		return DecimalValue{}, errDecimalOverflow
+	}
+
+	d := DecimalValue{Coef: int64(coef), Scale: int32(scale)}
+	if neg {
+		d.Coef = -d.Coef
+	}
+	return d, nil
+}
+
+// String formats the decimal without an exponent.
+func (d DecimalValue) String() string {
+	u := uint64(d.Coef)
+	if d.Coef < 0 {
+		u = -u
+	}
+	digits := strconv.FormatUint(u, 10)
+
+	var b []byte
+	if d.Coef < 0 {
+		b = append(b, '-')
+	}
+	switch {
+	case d.Scale <= 0:
+		b = append(b, digits...)
+		if u != 0 {
+			for i := int32(0); i < -d.Scale; i++ {
+				b = append(b, '0')
+			}
+		}
+	case int(d.Scale) < len(digits):
+		n := len(digits) - int(d.Scale)
+		b = append(b, digits[:n]...)
+		b = append(b, '.')
+		b = append(b, digits[n:]...)
+	default:
+		b = append(b, "0."...)
+		for i := len(digits); i < int(d.Scale); i++ {
+			b = append(b, '0')
+		}
+		b = append(b, digits...)
+	}
+	return string(b)
+}
+
+// Decimal is a nullable fixed-point decimal with optional semantics. It is decoded from both
+// numbers and strings, Quoted tells which of the forms is used when it is encoded.
+type Decimal struct {
+	Value  DecimalValue
+	Valid  bool
+	Set    bool
+	Quoted bool
+}
+
+func (v *Decimal) SetValue(val DecimalValue) {
+	v.Value = val
+	v.Set = true
+	v.Valid = true
+}
+
+func (v *Decimal) SetNull() {
+	v.Set = true
+	v.Valid = false
+}
+
+func (v *Decimal) IsValid() bool {
+	return v.Valid
+}
+
+func (v *Decimal) IsSet() bool {
+	return v.Set
+}
+
+func (v *Decimal) Get() DecimalValue {
+	return v.Value
+}
+
+// MarshalPartialJSON does JSON marshaling using partialencode interface.
+func (v Decimal) MarshalPartialJSON(w *jwriter.Writer) {
+	switch {
+	case !v.Valid:
+		w.RawString("null")
+	case v.Quoted:
+		w.String(v.Value.String())
+	default:
+		w.RawString(v.Value.String())
+	}
+}
+
+// UnMarshalPartialJSON does JSON unmarshaling using partialencode interface.
+func (v *Decimal) UnMarshalPartialJSON(l *jlexer.Lexer) {
+	if l.IsNull() {
+		l.Skip()
+		v.SetNull()
+		return
+	}
+
+	quoted := l.PeekKind() == jlexer.String
+	s := l.JsonNumber()
+	if !l.Ok() {
+		return
+	}
+	d, err := ParseDecimal(string(s))
+	if err != nil {
+		l.AddNonFatalError(err)
+		return
+	}
+	v.SetValue(d)
+	v.Quoted = quoted
+}
+
+// MarshalJSON implements a standard json marshaler interface.
+func (v Decimal) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	v.MarshalPartialJSON(&w)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// UnmarshalJSON implements a standard json unmarshaler interface.
+func (v *Decimal) UnmarshalJSON(data []byte) error {
+	l := jlexer.Lexer{Data: data}
+	v.UnMarshalPartialJSON(&l)
+	return l.Error()
+}
+
+// String implements a stringer interface.
+func (v Decimal) String() string {
+	if !v.Set {
+		return "<undefined>"
+	}
+	if !v.Valid {
+		return "null"
+	}
+	return v.Value.String()
+}
@@ -0,0 +1,87 @@
+// generated by gotemplate
+
+package basic
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/reddyvinod/partialencode/jlexer"
+	"github.com/reddyvinod/partialencode/jwriter"
+)
+
+// template type Duration(time.Duration)
+
+// time.Duration 'gotemplate'-based type for providing optional semantics without using pointers.
+type Duration struct {
+	Value time.Duration
+	Valid bool
+	Set   bool
+}
+
+func (v *Duration) SetValue(val time.Duration) {
+	v.Value = val
+	v.Set = true
+	v.Valid = true
+}
+
+func (v *Duration) SetNull() {
+	v.Set = true
+	v.Valid = false
+}
+
+func (v *Duration) IsValid() bool {
+	return v.Valid
+}
+
+func (v *Duration) IsSet() bool {
+	return v.Set
+}
+
+func (v *Duration) Get() time.Duration {
+	return v.Value
+}
+
+// MarshalPartialJSON does JSON marshaling using partialencode interface.
+func (v Duration) MarshalPartialJSON(w *jwriter.Writer) {
+	if v.Valid {
+		w.Duration(v.Value)
+	} else {
+		w.RawString("null")
+	}
+}
+
+// UnMarshalPartialJSON does JSON unmarshaling using partialencode interface.
+func (v *Duration) UnMarshalPartialJSON(l *jlexer.Lexer) {
+	if l.IsNull() {
+		l.Skip()
+		v.SetNull()
+	} else {
+		v.SetValue(l.Duration())
+	}
+}
+
+// MarshalJSON implements a standard json marshaler interface.
+func (v Duration) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	v.MarshalPartialJSON(&w)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// UnmarshalJSON implements a standard json unmarshaler interface.
+func (v *Duration) UnmarshalJSON(data []byte) error {
+	l := jlexer.Lexer{Data: data}
+	v.UnMarshalPartialJSON(&l)
+	return l.Error()
+}
+
+// String implements a stringer interface using fmt.Sprint for the value.
+func (v Duration) String() string {
+	if !v.Set {
+		return "<undefined>"
+	}
+	if !v.Valid {
+		return "null"
+	}
+	return fmt.Sprint(v.Value)
+}
@@ -0,0 +1,87 @@
+// generated by gotemplate
+
+package basic
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/reddyvinod/partialencode/jlexer"
+	"github.com/reddyvinod/partialencode/jwriter"
+)
+
+// template type Time(time.Time)
+
+// time.Time 'gotemplate'-based type for providing optional semantics without using pointers.
+type Time struct {
+	Value time.Time
+	Valid bool
+	Set   bool
+}
+
+func (v *Time) SetValue(val time.Time) {
+	v.Value = val
+	v.Set = true
+	v.Valid = true
+}
+
+func (v *Time) SetNull() {
+	v.Set = true
+	v.Valid = false
+}
+
+func (v *Time) IsValid() bool {
+	return v.Valid
+}
+
+func (v *Time) IsSet() bool {
+	return v.Set
+}
+
+func (v *Time) Get() time.Time {
+	return v.Value
+}
+
+// MarshalPartialJSON does JSON marshaling using partialencode interface.
+func (v Time) MarshalPartialJSON(w *jwriter.Writer) {
+	if v.Valid {
+		w.Time(v.Value)
+	} else {
+		w.RawString("null")
+	}
+}
+
+// UnMarshalPartialJSON does JSON unmarshaling using partialencode interface.
+func (v *Time) UnMarshalPartialJSON(l *jlexer.Lexer) {
+	if l.IsNull() {
+		l.Skip()
+		v.SetNull()
+	} else {
+		v.SetValue(l.Time())
+	}
+}
+
+// MarshalJSON implements a standard json marshaler interface.
+func (v Time) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	v.MarshalPartialJSON(&w)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// UnmarshalJSON implements a standard json unmarshaler interface.
+func (v *Time) UnmarshalJSON(data []byte) error {
+	l := jlexer.Lexer{Data: data}
+	v.UnMarshalPartialJSON(&l)
+	return l.Error()
+}
+
+// String implements a stringer interface using fmt.Sprint for the value.
+func (v Time) String() string {
+	if !v.Set {
+		return "<undefined>"
+	}
+	if !v.Valid {
+		return "null"
+	}
+	return fmt.Sprint(v.Value)
+}
@@ -0,0 +1,87 @@
+package basic
+
+import (
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	for i, test := range []struct {
+		s         string
+		want      DecimalValue
+		wantStr   string
+		wantError bool
+	}{
+		{s: "0", want: DecimalValue{}, wantStr: "0"},
+		{s: "12.50", want: DecimalValue{1250, 2}, wantStr: "12.50"},
+		{s: "-0.05", want: DecimalValue{-5, 2}, wantStr: "-0.05"},
+		{s: "1.5e3", want: DecimalValue{15, -2}, wantStr: "1500"},
+		{s: "25E-4", want: DecimalValue{25, 4}, wantStr: "0.0025"},
+		{s: "9223372036854775807", want: DecimalValue{1<<63 - 1, 0}, wantStr: "9223372036854775807"},
+		{s: "-92233720368547758.07", want: DecimalValue{-(1<<63 - 1), 2}, wantStr: "-92233720368547758.07"},
+
+		{s: "9223372036854775808", wantError: true},
+		{s: "1e100", wantError: true},
+		{s: "", wantError: true},
+		{s: "-", wantError: true},
+		{s: "1.2.3", wantError: true},
+		{s: "1e", wantError: true},
+		{s: "abc", wantError: true},
+	} {
+		got, err := ParseDecimal(test.s)
+		if err != nil {
+			if !test.wantError {
+				t.Errorf("[%d, %q] ParseDecimal() error: %v", i, test.s, err)
+			}
+			continue
+		}
+		if test.wantError {
+			t.Errorf("[%d, %q] ParseDecimal() ok; want error", i, test.s)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("[%d, %q] ParseDecimal() = %+v; want %+v", i, test.s, got, test.want)
+		}
+		if s := got.String(); s != test.wantStr {
+			t.Errorf("[%d, %q] String() = %q; want %q", i, test.s, s, test.wantStr)
+		}
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	for i, test := range []struct {
+		data      string
+		want      Decimal
+		wantError bool
+	}{
+		{data: `19.99`, want: Decimal{Value: DecimalValue{1999, 2}, Valid: true, Set: true}},
+		{data: `"19.990"`, want: Decimal{Value: DecimalValue{19990, 3}, Valid: true, Set: true, Quoted: true}},
+		{data: `null`, want: Decimal{Set: true}},
+
+		{data: `"x"`, wantError: true},
+		{data: `true`, wantError: true},
+	} {
+		var got Decimal
+		err := got.UnmarshalJSON([]byte(test.data))
+		if err != nil {
+			if !test.wantError {
+				t.Errorf("[%d, %s] UnmarshalJSON() error: %v", i, test.data, err)
+			}
+			continue
+		}
+		if test.wantError {
+			t.Errorf("[%d, %s] UnmarshalJSON() ok; want error", i, test.data)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("[%d, %s] UnmarshalJSON() = %+v; want %+v", i, test.data, got, test.want)
+		}
+
+		data, err := got.MarshalJSON()
+		if err != nil {
+			t.Errorf("[%d, %s] MarshalJSON() error: %v", i, test.data, err)
+		}
+		if string(data) != test.data {
+			t.Errorf("[%d, %s] MarshalJSON() = %s; want %s", i, test.data, data, test.data)
+		}
+	}
+}
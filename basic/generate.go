@@ -19,4 +19,7 @@ package basic
 
 //go:generate gotemplate -outfmt %v "github.com/reddyvinod/partialencode/basic/template" Bool(bool)
 //go:generate gotemplate -outfmt %v "github.com/reddyvinod/partialencode/basic/template" String(string)
+
+//go:generate gotemplate -outfmt %v "github.com/reddyvinod/partialencode/basic/template" Time(time.Time)
+//go:generate gotemplate -outfmt %v "github.com/reddyvinod/partialencode/basic/template" Duration(time.Duration)
 //go:generate sed -i ".bak" "s/generated by gotemplate/+build none/" template/BasicType.go
@@ -50,9 +50,16 @@ func (g *Generator) writeDeEncode() error {
 		}
 
 		fmt.Fprintln(f, "func (", t, ") MarshalPartialJSON(w *jwriter.Writer) {}")
+		if g.FullMarshalers {
+			fmt.Fprintln(f, "func (", t, ") MarshalFullJSON(w *jwriter.Writer) {}")
+		}
 		fmt.Fprintln(f, "func (*", t, ") UnMarshalPartialJSON(l *jlexer.Lexer) {}")
 		fmt.Fprintln(f)
 		fmt.Fprintln(f, "type Partial_exporter_"+t+" *"+t)
+		if g.LazyPartials && !strings.HasPrefix(t, "PartialBool") {
+			fmt.Fprintln(f)
+			fmt.Fprintln(f, "type Lazy"+t+" struct{}")
+		}
 	}
 	return nil
 }
@@ -103,6 +110,15 @@ func (g *Generator) writeDeEncodeMain() (path string, err error) {
 	if g.DisallowUnknownFields {
 		fmt.Fprintln(f, "  g.DisallowUnknownFields()")
 	}
+	if g.LazyPartials {
+		fmt.Fprintln(f, "  g.LazyPartials()")
+	}
+	if g.UnsetNull {
+		fmt.Fprintln(f, "  g.UnsetNull()")
+	}
+	if g.FullMarshalers {
+		fmt.Fprintln(f, "  g.FullMarshalers()")
+	}
 
 	sort.Strings(g.Types)
 	for _, v := range g.Types {
@@ -110,6 +126,7 @@ func (g *Generator) writeDeEncodeMain() (path string, err error) {
 			fmt.Fprintln(f, "  g.Add(pkg.Partial_exporter_"+v+"(nil))")
 		}
 	}
+	g.writeAddVariants(f)
 
 	fmt.Fprintln(f, "  if err := g.Run(os.Stdout); err != nil {")
 	fmt.Fprintln(f, "    fmt.Fprintln(os.Stderr, err)")
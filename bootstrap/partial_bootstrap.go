@@ -12,6 +12,10 @@ import (
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/reddyvinod/partialencode/parser"
 )
 
 const genPackage = "github.com/reddyvinod/partialencode/gen"
@@ -21,15 +25,28 @@ const pkgLexer = "github.com/reddyvinod/partialencode/jlexer"
 type Generator struct {
 	PkgPath, PkgName string
 	Types            []string
+	Enums            []parser.Enum
+	Variants         []parser.Variant
+	DefaultsHooks    []parser.Hook
 
 	NoStdMarshalers       bool
 	SnakeCase             bool
 	LowerCamelCase        bool
 	OmitEmpty             bool
 	DisallowUnknownFields bool
+	LazyPartials          bool
+	UnsetNull             bool
+	FullMarshalers        bool
+	Tracked               bool
+	Merge                 bool
+	Compose               bool
+	Inverse               bool
+	Changes               bool
+	DeepCopy              bool
 
 	PartialName   string
 	DeEncoderName string
+	SchemaName    string
 	BuildTags     string
 
 	StubsOnly  bool
@@ -56,10 +73,31 @@ func (g *Generator) writePartial() error {
 	fmt.Fprintln(f, "package ", g.PkgName)
 	fmt.Fprintln(f)
 
+	if len(g.Enums) > 0 {
+		fmt.Fprintln(f, "import (")
+		fmt.Fprintln(f, `  "`+pkgWriter+`"`)
+		fmt.Fprintln(f, `  "`+pkgLexer+`"`)
+		fmt.Fprintln(f, ")")
+		fmt.Fprintln(f)
+	}
+
 	sort.Strings(g.Types)
 	for _, t := range g.Types {
 		fmt.Fprintln(f, "type Partial_exporter_"+t+" *"+t)
 	}
+	for _, e := range g.Enums {
+		t := e.Name
+		fmt.Fprintln(f)
+		fmt.Fprintln(f, "func (", t, ") Values() []", t, "{ return nil }")
+		fmt.Fprintln(f, "func (", t, ") IsValid() bool { return false }")
+		if !g.NoStdMarshalers {
+			fmt.Fprintln(f, "func (", t, ") MarshalJSON() ([]byte, error) { return nil, nil }")
+			fmt.Fprintln(f, "func (*", t, ") UnmarshalJSON([]byte) error { return nil }")
+		}
+		fmt.Fprintln(f, "func (", t, ") MarshalPartialJSON(w *jwriter.Writer) {}")
+		fmt.Fprintln(f, "func (*", t, ") UnMarshalPartialJSON(l *jlexer.Lexer) {}")
+		fmt.Fprintln(f, "type Partial_exporter_"+t+" *"+t)
+	}
 	return nil
 }
 
@@ -82,7 +120,7 @@ func (g *Generator) writePartialMain() (path string, err error) {
 	fmt.Fprintln(f, `  "os"`)
 	fmt.Fprintln(f)
 	fmt.Fprintf(f, "  %q\n", genPackage)
-	if len(g.Types) > 0 {
+	if len(g.Types) > 0 || len(g.Enums) > 0 {
 		fmt.Fprintln(f)
 		fmt.Fprintf(f, "  pkg %q\n", g.PkgPath)
 	}
@@ -95,10 +133,49 @@ func (g *Generator) writePartialMain() (path string, err error) {
 		fmt.Fprintf(f, "  g.SetBuildTags(%q)\n", g.BuildTags)
 	}
 
+	if g.SnakeCase {
+		fmt.Fprintln(f, "  g.UseSnakeCase()")
+	}
+	if g.LowerCamelCase {
+		fmt.Fprintln(f, "  g.UseLowerCamelCase()")
+	}
+	if g.Tracked {
+		fmt.Fprintln(f, "  g.Tracked()")
+	}
+	if g.Merge {
+		fmt.Fprintln(f, "  g.Merge()")
+	}
+	if g.Compose {
+		fmt.Fprintln(f, "  g.Compose()")
+	}
+	if g.Inverse {
+		fmt.Fprintln(f, "  g.Inverse()")
+	}
+	if g.Changes {
+		fmt.Fprintln(f, "  g.Changes()")
+	}
+	if g.DeepCopy {
+		fmt.Fprintln(f, "  g.DeepCopy()")
+	}
+
 	sort.Strings(g.Types)
 	for _, v := range g.Types {
 		fmt.Fprintln(f, "  g.Add(pkg.Partial_exporter_"+v+"(nil))")
 	}
+	for _, e := range g.Enums {
+		args := []string{"pkg.Partial_exporter_" + e.Name + "(nil)", strconv.Quote(e.Fallback)}
+		for _, v := range e.Values {
+			args = append(args, strconv.Quote(v))
+		}
+		fmt.Fprintln(f, "  g.AddEnum("+strings.Join(args, ", ")+")")
+	}
+	g.writeAddVariants(f)
+	for _, h := range g.DefaultsHooks {
+		// Hooks of types without partial structs are not called.
+		if i := sort.SearchStrings(g.Types, h.Type); i < len(g.Types) && g.Types[i] == h.Type {
+			fmt.Fprintf(f, "  g.AddDefaultsHook(pkg.Partial_exporter_%s(nil), %q)\n", h.Type, h.Method)
+		}
+	}
 
 	fmt.Fprintln(f, "  if err := g.Run(os.Stdout); err != nil {")
 	fmt.Fprintln(f, "    fmt.Fprintln(os.Stderr, err)")
@@ -161,3 +238,11 @@ func (g *Generator) RunPartial() error {
 
 	return os.Rename(f.Name(), g.PartialName)
 }
+
+// writeAddVariants outputs the registration of the variants of interfaces in the bootstrapping
+// code.
+func (g *Generator) writeAddVariants(f *os.File) {
+	for _, v := range g.Variants {
+		fmt.Fprintf(f, "  g.AddVariant(pkg.Partial_exporter_%s(nil), %q, %q, %q)\n", v.Name, v.Interface, v.Key, v.Value)
+	}
+}
@@ -0,0 +1,151 @@
+package bootstrap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// writeSchemaExporter outputs the file exporting the types of the package to the bootstrapping
+// code. Unlike the stubs of the other stages, it is added to the generated code, which the
+// package needs to compile.
+func (g *Generator) writeSchemaExporter() (path string, err error) {
+	path = strings.TrimSuffix(g.SchemaName, filepath.Ext(g.SchemaName)) + "_exporter.go"
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if g.BuildTags != "" {
+		fmt.Fprintln(f, "// +build ", g.BuildTags)
+		fmt.Fprintln(f)
+	}
+	fmt.Fprintln(f, "// TEMPORARY AUTOGENERATED FILE: partialencode code exporting the types of the")
+	fmt.Fprintln(f, "// package during schema generation.")
+	fmt.Fprintln(f)
+	fmt.Fprintln(f, "package ", g.PkgName)
+	fmt.Fprintln(f)
+
+	sort.Strings(g.Types)
+	for _, t := range g.Types {
+		fmt.Fprintln(f, "type Schema_exporter_"+t+" *"+t)
+	}
+	for _, e := range g.Enums {
+		fmt.Fprintln(f, "type Schema_exporter_"+e.Name+" *"+e.Name)
+	}
+	return path, nil
+}
+
+// writeSchemaMain creates a .go file that launches the schema generator if 'go run'.
+func (g *Generator) writeSchemaMain() (path string, err error) {
+	f, err := ioutil.TempFile(filepath.Dir(g.SchemaName), "partialencode-bootstrap")
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprintln(f, "// +build ignore")
+	fmt.Fprintln(f)
+	fmt.Fprintln(f, "// TEMPORARY AUTOGENERATED FILE: partialencode bootstapping code to launch")
+	fmt.Fprintln(f, "// the actual generator.")
+	fmt.Fprintln(f)
+	fmt.Fprintln(f, "package main")
+	fmt.Fprintln(f)
+	fmt.Fprintln(f, "import (")
+	fmt.Fprintln(f, `  "fmt"`)
+	fmt.Fprintln(f, `  "os"`)
+	fmt.Fprintln(f)
+	fmt.Fprintf(f, "  %q\n", genPackage)
+	if len(g.Types) > 0 || len(g.Enums) > 0 {
+		fmt.Fprintln(f)
+		fmt.Fprintf(f, "  pkg %q\n", g.PkgPath)
+	}
+	fmt.Fprintln(f, ")")
+	fmt.Fprintln(f)
+	fmt.Fprintln(f, "func main() {")
+	fmt.Fprintln(f, "  g := gen.NewSchemaGenerator()")
+	fmt.Fprintf(f, "  g.SetPkg(%q, %q)\n", g.PkgName, g.PkgPath)
+	if g.SnakeCase {
+		fmt.Fprintln(f, "  g.UseSnakeCase()")
+	}
+	if g.LowerCamelCase {
+		fmt.Fprintln(f, "  g.UseLowerCamelCase()")
+	}
+	if g.DisallowUnknownFields {
+		fmt.Fprintln(f, "  g.DisallowUnknownFields()")
+	}
+
+	sort.Strings(g.Types)
+	for _, v := range g.Types {
+		fmt.Fprintln(f, "  g.Add(pkg.Schema_exporter_"+v+"(nil))")
+	}
+	for _, e := range g.Enums {
+		args := []string{"pkg.Schema_exporter_" + e.Name + "(nil)", strconv.Quote(e.Fallback)}
+		for _, v := range e.Values {
+			args = append(args, strconv.Quote(v))
+		}
+		fmt.Fprintln(f, "  g.AddEnum("+strings.Join(args, ", ")+")")
+	}
+	for _, v := range g.Variants {
+		fmt.Fprintf(f, "  g.AddVariant(pkg.Schema_exporter_%s(nil), %q, %q, %q)\n", v.Name, v.Interface, v.Key, v.Value)
+	}
+
+	fmt.Fprintln(f, "  if err := g.Run(os.Stdout); err != nil {")
+	fmt.Fprintln(f, "    fmt.Fprintln(os.Stderr, err)")
+	fmt.Fprintln(f, "    os.Exit(1)")
+	fmt.Fprintln(f, "  }")
+	fmt.Fprintln(f, "}")
+
+	src := f.Name()
+	if err := f.Close(); err != nil {
+		return src, err
+	}
+
+	dest := src + ".go"
+	return dest, os.Rename(src, dest)
+}
+
+// RunSchema generates the JSON Schema of the types into SchemaName. The package must compile
+// with the generated partial structs and de/encoders, since the schema of enums is read from
+// their generated methods.
+func (g *Generator) RunSchema() error {
+	exporter, err := g.writeSchemaExporter()
+	if err != nil {
+		return err
+	}
+	if !g.LeaveTemps {
+		defer os.Remove(exporter)
+	}
+
+	path, err := g.writeSchemaMain()
+	if err != nil {
+		return err
+	}
+	if !g.LeaveTemps {
+		defer os.Remove(path)
+	}
+
+	f, err := os.Create(g.SchemaName + ".tmp")
+	if err != nil {
+		return err
+	}
+	if !g.LeaveTemps {
+		defer os.Remove(f.Name()) // will not remove after rename
+	}
+
+	cmd := exec.Command("go", "run", "-tags", g.BuildTags, filepath.Base(path))
+	cmd.Stdout = f
+	cmd.Stderr = os.Stderr
+	cmd.Dir = filepath.Dir(path)
+	if err = cmd.Run(); err != nil {
+		return err
+	}
+
+	f.Close()
+	return os.Rename(f.Name(), g.SchemaName)
+}
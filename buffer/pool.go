@@ -3,6 +3,7 @@
 package buffer
 
 import (
+	"errors"
 	"io"
 	"sync"
 )
@@ -20,6 +21,10 @@ var config = PoolConfig{
 	MaxSize:    32768,
 }
 
+// ErrStreamed is returned by DumpTo of a buffer streamed with StreamTo, which does not hold all
+// of the data.
+var ErrStreamed = errors.New("buffer: data is streamed, use Flush")
+
 // Reuse pool: chunk size -> pool.
 var buffers = map[int]*sync.Pool{}
 
@@ -71,8 +76,76 @@ type Buffer struct {
 	// Buf is the current chunk that can be used for serialization.
 	Buf []byte
 
+	// OnError is called with the first error of the stream set with StreamTo as soon as a
+	// write fails, so that the serialization can be stopped.
+	OnError func(err error)
+
 	toPool []byte
 	bufs   [][]byte
+
+	out       io.Writer // If set, full chunks are written out once threshold bytes are buffered.
+	threshold int
+	written   int   // Number of bytes written to out.
+	err       error // First error returned by out.
+}
+
+// StreamTo makes the buffer write full chunks to w as soon as at least threshold bytes are
+// buffered, so that the buffer does not hold the whole output. Flush must be called to write
+// out the remaining data. After a write error the data is discarded, the error is passed to
+// OnError and returned by Flush.
+func (b *Buffer) StreamTo(w io.Writer, threshold int) {
+	b.out = w
+	b.threshold = threshold
+}
+
+// Streamed returns whether the buffer is streamed with StreamTo.
+func (b *Buffer) Streamed() bool {
+	return b.out != nil
+}
+
+// Written returns the number of bytes written out to the stream set with StreamTo.
+func (b *Buffer) Written() int {
+	return b.written
+}
+
+// Flush writes all the buffered data to the stream set with StreamTo and returns the number
+// of bytes written. It does nothing if the buffer is not streamed.
+func (b *Buffer) Flush() (written int, err error) {
+	if b.out == nil {
+		return 0, nil
+	}
+	written = b.flushChunks()
+	if b.err == nil && len(b.Buf) > 0 {
+		written += b.write(b.Buf)
+	}
+	b.Buf = b.Buf[:0]
+
+	return written, b.err
+}
+
+// write writes data to the stream, recording the first error.
+func (b *Buffer) write(data []byte) int {
+	n, err := b.out.Write(data)
+	b.written += n
+	if err != nil {
+		b.err = err
+		if b.OnError != nil {
+			b.OnError(err)
+		}
+	}
+	return n
+}
+
+// flushChunks writes full chunks to the stream and releases them.
+func (b *Buffer) flushChunks() (written int) {
+	for _, buf := range b.bufs {
+		if b.err == nil {
+			written += b.write(buf)
+		}
+		putBuf(buf)
+	}
+	b.bufs = b.bufs[:0]
+	return written
 }
 
 // EnsureSpace makes sure that the current chunk contains at least s free bytes,
@@ -92,6 +165,11 @@ func (b *Buffer) EnsureSpace(s int) {
 		}
 		b.bufs = append(b.bufs, b.Buf)
 		l = cap(b.toPool) * 2
+
+		// After a write error the data is dropped right away.
+		if b.out != nil && (b.err != nil || b.Size() >= b.threshold) {
+			b.flushChunks()
+		}
 	} else {
 		l = config.StartSize
 	}
@@ -154,8 +232,12 @@ func (b *Buffer) Size() int {
 	return size
 }
 
-// DumpTo outputs the contents of a buffer to a writer and resets the buffer.
+// DumpTo outputs the contents of a buffer to a writer and resets the buffer. It returns
+// ErrStreamed for a streamed buffer, whose data was partly written out.
 func (b *Buffer) DumpTo(w io.Writer) (written int, err error) {
+	if b.out != nil {
+		return 0, ErrStreamed
+	}
 	var n int
 	for _, buf := range b.bufs {
 		if err == nil {
@@ -2,6 +2,7 @@ package buffer
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 )
 
@@ -105,3 +106,73 @@ func TestReadCloser(t *testing.T) {
 		t.Errorf("DumpTo() = %v; want %v", n, len(want))
 	}
 }
+
+func TestStreamTo(t *testing.T) {
+	var b Buffer
+	var want []byte
+	var out bytes.Buffer
+
+	b.StreamTo(&out, 1024)
+
+	s := "test"
+	for i := 0; i < 1000; i++ {
+		b.AppendString(s)
+		want = append(want, s...)
+	}
+
+	if out.Len() == 0 {
+		t.Errorf("nothing written before Flush()")
+	}
+	if b.Size() > 1024+config.MaxSize {
+		t.Errorf("Size() = %v; want at most %v", b.Size(), 1024+config.MaxSize)
+	}
+
+	if _, err := b.Flush(); err != nil {
+		t.Errorf("Flush() error: %v", err)
+	}
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("StreamTo() = %v; want %v", got, want)
+	}
+	if b.Written() != len(want) {
+		t.Errorf("Written() = %v; want %v", b.Written(), len(want))
+	}
+}
+
+type errorWriter struct {
+	n int
+}
+
+func (w *errorWriter) Write(p []byte) (int, error) {
+	if w.n < len(p) {
+		n := w.n
+		w.n = 0
+		return n, errors.New("write failed")
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestStreamToError(t *testing.T) {
+	var b Buffer
+	var onError error
+
+	b.StreamTo(&errorWriter{n: 100}, 0)
+	b.OnError = func(err error) { onError = err }
+
+	for i := 0; i < 1000; i++ {
+		b.AppendString("test")
+	}
+	if onError == nil {
+		t.Errorf("OnError not called before Flush()")
+	}
+
+	if _, err := b.Flush(); err == nil || err != onError {
+		t.Errorf("Flush() error: %v; want %v", err, onError)
+	}
+	if _, err := b.DumpTo(&bytes.Buffer{}); err != ErrStreamed {
+		t.Errorf("DumpTo() error: %v; want %v", err, ErrStreamed)
+	}
+	if b.Written() != 100 {
+		t.Errorf("Written() = %v; want 100", b.Written())
+	}
+}
@@ -0,0 +1,103 @@
+package partialencode
+
+import (
+	"encoding/json"
+
+	"github.com/reddyvinod/partialencode/jlexer"
+	"github.com/reddyvinod/partialencode/jwriter"
+)
+
+// Op is the operation a partial performs on a field.
+type Op string
+
+const (
+	OpSet  Op = "set"  // The field is set to a value.
+	OpNull Op = "null" // The field is set to null.
+)
+
+// FieldChange is a change of a field made by a partial, as recorded by the generated
+// ChangesPartial<T> funcs. Assignments that keep the value of a field are skipped rather than
+// recorded, so no change has an op for unchanged fields.
+type FieldChange struct {
+	Path      string      // JSON path of the field, e.g. $.address.city.
+	Old       interface{} // Value of the field before the change.
+	New       interface{} // Value of the field after the change, nil if it is set to null.
+	Op        Op
+	Sensitive bool // The values are masked when encoded.
+
+	// Encoders of Old and New, which write them as the partial of the struct of the field
+	// does. Values without an encoder are written with encoding/json.
+	OldJSON, NewJSON Marshaler
+}
+
+// MarshalPartialJSON supports Marshaler interface. Values of sensitive fields are written
+// as jwriter.DefaultPlaceholder.
+func (c FieldChange) MarshalPartialJSON(w *jwriter.Writer) {
+	w.RawString(`{"path":`)
+	w.String(c.Path)
+	w.RawString(`,"op":`)
+	w.String(string(c.Op))
+	w.RawString(`,"old":`)
+	c.writeValue(w, c.Old, c.OldJSON)
+	w.RawString(`,"new":`)
+	c.writeValue(w, c.New, c.NewJSON)
+	w.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface.
+func (c FieldChange) MarshalJSON() ([]byte, error) {
+	return Marshal(c)
+}
+
+func (c FieldChange) writeValue(w *jwriter.Writer, v interface{}, enc Marshaler) {
+	if c.Sensitive {
+		data, err := json.Marshal(v)
+		if err == nil && string(data) != "null" {
+			w.String(jwriter.DefaultPlaceholder)
+			return
+		}
+		w.Raw(data, err)
+		return
+	}
+	if enc == nil {
+		enc, _ = v.(Marshaler)
+	}
+	if enc != nil {
+		enc.MarshalPartialJSON(w)
+		return
+	}
+	w.Raw(json.Marshal(v))
+}
+
+// Member writes the value of the member Key of the object Object encodes, e.g. a field of a
+// partial struct with only that field valid. An empty Key writes the whole object, as for
+// embedded structs whose fields are inlined. A missing member is written as null.
+type Member struct {
+	Object Marshaler
+	Key    string
+}
+
+// MarshalPartialJSON supports Marshaler interface.
+func (m Member) MarshalPartialJSON(w *jwriter.Writer) {
+	var out jwriter.Writer
+	m.Object.MarshalPartialJSON(&out)
+	data, err := out.BuildBytes()
+	if err != nil || m.Key == "" {
+		w.Raw(data, err)
+		return
+	}
+
+	in := jlexer.Lexer{Data: data}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if key == m.Key {
+			w.Raw(in.Raw(), in.Error())
+			return
+		}
+		in.SkipRecursive()
+		in.WantComma()
+	}
+	w.Raw(nil, in.Error())
+}
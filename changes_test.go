@@ -0,0 +1,52 @@
+package partialencode
+
+import (
+	"testing"
+
+	"github.com/reddyvinod/partialencode/jwriter"
+)
+
+type rawObject string
+
+func (o rawObject) MarshalPartialJSON(w *jwriter.Writer) { w.RawString(string(o)) }
+
+func TestFieldChangeMarshal(t *testing.T) {
+	for i, test := range []struct {
+		Change FieldChange
+		Want   string
+	}{
+		{FieldChange{Path: "$.name", Old: "a", New: "b", Op: OpSet}, `{"path":"$.name","op":"set","old":"a","new":"b"}`},
+		{FieldChange{Path: "$.age", Old: 3, Op: OpNull}, `{"path":"$.age","op":"null","old":3,"new":null}`},
+		{FieldChange{Path: `$["a b"]`, Old: []int{1}, New: map[string]int{"x": 1}, Op: OpSet}, `{"path":"$[\"a b\"]","op":"set","old":[1],"new":{"x":1}}`},
+		{FieldChange{Path: "$.password", Old: "a", New: "b", Op: OpSet, Sensitive: true}, `{"path":"$.password","op":"set","old":"***","new":"***"}`},
+		{FieldChange{Path: "$.token", Old: (*string)(nil), New: "b", Op: OpSet, Sensitive: true}, `{"path":"$.token","op":"set","old":null,"new":"***"}`},
+		{
+			FieldChange{Path: "$.rank", Old: 1, New: 2, Op: OpSet, OldJSON: rawObject(`"1"`), NewJSON: rawObject(`"2"`)},
+			`{"path":"$.rank","op":"set","old":"1","new":"2"}`,
+		},
+	} {
+		data, err := test.Change.MarshalJSON()
+		if err != nil {
+			t.Errorf("[%d] MarshalJSON() error: %v", i, err)
+		} else if string(data) != test.Want {
+			t.Errorf("[%d] MarshalJSON() = %s; want %s", i, data, test.Want)
+		}
+	}
+}
+
+func TestMember(t *testing.T) {
+	for i, test := range []struct {
+		Object, Key, Want string
+	}{
+		{`{"type":"circle","r":{"x":[1,2]},"s":2}`, "r", `{"x":[1,2]}`},
+		{`{"type":"circle","r":1}`, "s", `null`},
+		{`{"a":1,"b":2}`, "", `{"a":1,"b":2}`},
+	} {
+		data, err := Marshal(Member{Object: rawObject(test.Object), Key: test.Key})
+		if err != nil {
+			t.Errorf("[%d] Marshal() error: %v", i, err)
+		} else if string(data) != test.Want {
+			t.Errorf("[%d] Marshal() = %s; want %s", i, data, test.Want)
+		}
+	}
+}
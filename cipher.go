@@ -0,0 +1,98 @@
+package partialencode
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/reddyvinod/partialencode/jlexer"
+	"github.com/reddyvinod/partialencode/jwriter"
+)
+
+// FieldCipher encrypts the values of fields tagged with partial:"encrypt=name", for the name it
+// is registered with. The path of the field is passed as associated data, so that a ciphertext
+// only decrypts as the value of the field it was written for.
+type FieldCipher interface {
+	Encrypt(plaintext, ad []byte) ([]byte, error)
+	Decrypt(ciphertext, ad []byte) ([]byte, error)
+}
+
+var (
+	fieldCiphersMu sync.RWMutex
+	fieldCiphers   = map[string]FieldCipher{}
+)
+
+// RegisterFieldCipher registers the cipher of the fields tagged with encrypt=name. Registering
+// nil removes the cipher.
+func RegisterFieldCipher(name string, c FieldCipher) {
+	fieldCiphersMu.Lock()
+	defer fieldCiphersMu.Unlock()
+	if c == nil {
+		delete(fieldCiphers, name)
+	} else {
+		fieldCiphers[name] = c
+	}
+}
+
+func fieldCipher(name string) (FieldCipher, error) {
+	fieldCiphersMu.RLock()
+	defer fieldCiphersMu.RUnlock()
+	c, ok := fieldCiphers[name]
+	if !ok {
+		return nil, fmt.Errorf("partialencode: no field cipher registered for %q", name)
+	}
+	return c, nil
+}
+
+// EncryptField writes the value that encode writes encrypted with the cipher registered for
+// name, as a base64 string. The path is the name of the type and the JSON name of the field,
+// e.g. User.ssn. It is called by generated encoders.
+func EncryptField(out *jwriter.Writer, name, path string, encode func(out *jwriter.Writer)) {
+	c, err := fieldCipher(name)
+	if err != nil {
+		out.Error = err
+		return
+	}
+
+	v := jwriter.Writer{Flags: out.Flags, NoEscapeHTML: out.NoEscapeHTML, View: out.View}
+	encode(&v)
+	plaintext, err := v.BuildBytes()
+	if err != nil {
+		out.Error = err
+		return
+	}
+	ciphertext, err := c.Encrypt(plaintext, []byte(path))
+	if err != nil {
+		out.Error = fmt.Errorf("partialencode: cannot encrypt %s: %v", path, err)
+		return
+	}
+	out.Base64Bytes(ciphertext)
+}
+
+// DecryptField reads a value written by EncryptField and decodes the decrypted value with
+// decode. It is called by generated decoders.
+func DecryptField(in *jlexer.Lexer, name, path string, decode func(in *jlexer.Lexer)) {
+	ciphertext := in.Bytes()
+	if !in.Ok() {
+		return
+	}
+	c, err := fieldCipher(name)
+	if err != nil {
+		in.AddError(err)
+		return
+	}
+	plaintext, err := c.Decrypt(ciphertext, []byte(path))
+	if err != nil {
+		in.AddError(&jlexer.LexerError{
+			Reason: "cannot decrypt " + path + ": " + err.Error(),
+			Offset: in.GetPos(),
+		})
+		return
+	}
+
+	l := in.SubLexer(plaintext)
+	decode(l)
+	l.Consumed()
+	if err := l.Error(); err != nil {
+		in.AddError(err)
+	}
+}
@@ -0,0 +1,68 @@
+package partialencode
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/reddyvinod/partialencode/jlexer"
+)
+
+// DecodeErrors contains all the errors collected during decoding with
+// jlexer.Lexer.UseMultipleErrors set. It works with errors.Is and errors.As, which check
+// every collected error.
+type DecodeErrors []*jlexer.LexerError
+
+func (e DecodeErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "no decode errors"
+	case 1:
+		return e[0].Error()
+	}
+
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(len(e)))
+	b.WriteString(" decode errors: ")
+	for i, err := range e {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the collected errors.
+func (e DecodeErrors) Unwrap() []error {
+	ret := make([]error, len(e))
+	for i, err := range e {
+		ret[i] = err
+	}
+	return ret
+}
+
+// LexerErrors returns all the errors of the lexer: nil if there are none and DecodeErrors
+// with the non-fatal errors followed by the fatal one otherwise. A fatal error that is not a
+// *jlexer.LexerError, e.g. a read error, is returned as is.
+func LexerErrors(l *jlexer.Lexer) error {
+	errs := DecodeErrors(l.GetNonFatalErrors())
+	if err := l.Error(); err != nil {
+		lerr, ok := err.(*jlexer.LexerError)
+		if !ok {
+			return err
+		}
+		errs = append(errs[:len(errs):len(errs)], lerr)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// UnmarshalMultipleErrors decodes the JSON in data into the object, collecting all decode
+// errors instead of stopping at the first one. The errors are returned as DecodeErrors.
+func UnmarshalMultipleErrors(data []byte, v Unmarshaler) error {
+	l := jlexer.Lexer{Data: data, UseMultipleErrors: true}
+	v.UnMarshalPartialJSON(&l)
+	return LexerErrors(&l)
+}
@@ -0,0 +1,53 @@
+package partialencode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/reddyvinod/partialencode/jlexer"
+)
+
+// prices decodes a list of numbers like a generated decoder would.
+type prices []float64
+
+func (p *prices) UnMarshalPartialJSON(l *jlexer.Lexer) {
+	l.Delim('[')
+	for !l.IsDelim(']') {
+		*p = append(*p, l.Float64())
+		l.WantComma()
+	}
+	l.Delim(']')
+}
+
+func TestUnmarshalMultipleErrors(t *testing.T) {
+	var p prices
+	err := UnmarshalMultipleErrors([]byte(`[1, "a", 2, true]`), &p)
+
+	var errs DecodeErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("UnmarshalMultipleErrors() error = %v; want DecodeErrors", err)
+	}
+	if len(errs) != 2 || errs[0].Path != "$[1]" || errs[1].Path != "$[3]" {
+		t.Errorf("UnmarshalMultipleErrors() errors = %v; want errors at $[1] and $[3]", errs)
+	}
+
+	var lerr *jlexer.LexerError
+	if !errors.As(err, &lerr) || lerr != errs[0] {
+		t.Errorf("errors.As(*jlexer.LexerError) = %v; want %v", lerr, errs[0])
+	}
+
+	if err := UnmarshalMultipleErrors([]byte(`[1, 2]`), &p); err != nil {
+		t.Errorf("UnmarshalMultipleErrors() error: %v", err)
+	}
+}
+
+func TestLexerErrorsFatal(t *testing.T) {
+	l := jlexer.Lexer{Data: []byte(`[1, "a", 2 x]`), UseMultipleErrors: true}
+	var p prices
+	p.UnMarshalPartialJSON(&l)
+
+	errs, ok := LexerErrors(&l).(DecodeErrors)
+	if !ok || len(errs) != 2 {
+		t.Errorf("LexerErrors() = %v; want the collected and the fatal error", LexerErrors(&l))
+	}
+}
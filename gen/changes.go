@@ -0,0 +1,134 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/reddyvinod/partialencode/jlexer"
+)
+
+// Changes requests to generate ChangesPartial<T>, recording the changes partials make to
+// structs, and ApplyPartial<T>, which they are computed with.
+func (g *PartialGenerator) Changes() {
+	g.changes = true
+}
+
+// genChanges generates the function returning the changes a partial of t makes when applied
+// to a t.
+func (g *PartialGenerator) genChanges(t reflect.Type) {
+	sname := g.getStructName(t)
+	fname := g.helperName("changes", t)
+	pe := g.pkgAlias(pkgPartialEncode)
+
+	fmt.Fprintf(g.out, "// Changes%s returns the changes p makes when applied to orig, skipping the fields it\n", sname)
+	fmt.Fprintln(g.out, "// sets to the values they have. Changes of nested partials are recorded per field, and")
+	fmt.Fprintln(g.out, "// changes of fields tagged partial:\"sensitive\" are masked when encoded.")
+	fmt.Fprintln(g.out, "func Changes"+sname+"(orig "+t.Name()+", p "+sname+") []"+pe+".FieldChange {")
+	fmt.Fprintln(g.out, "  var cs []"+pe+".FieldChange")
+	fmt.Fprintln(g.out, "  "+fname+"(\"$\", orig, p, &cs)")
+	fmt.Fprintln(g.out, "  return cs")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	fmt.Fprintln(g.out, "func "+fname+"(path string, orig "+t.Name()+", p "+sname+", cs *[]"+pe+".FieldChange) {")
+	for i := 0; i < t.NumField(); i++ {
+		g.genFieldChanges(t, t.Field(i))
+	}
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+}
+
+func (g *PartialGenerator) genFieldChanges(t reflect.Type, f reflect.StructField) {
+	pe := g.pkgAlias(pkgPartialEncode)
+	tags := parseFieldTags(f)
+	orig, in := "orig."+f.Name, "p."+f.Name
+
+	// Embedded fields, whose members are inlined, are recorded under their type name.
+	name := g.fieldNamer.GetJSONFieldName(t, f)
+	if tags.omit || f.Anonymous {
+		name = f.Name
+	}
+	path := fmt.Sprintf("path + %q", jlexer.PathKey(name))
+
+	// Values are encoded by the partial of t holding only the field, with the options of the
+	// field. Fields of embedded structs are inlined in it.
+	key := name
+	if f.Anonymous {
+		key = ""
+	}
+	encoder := func(ws, v, out string) {
+		pVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"var "+pVar+" "+g.getStructName(t))
+		g.genFieldToPartial(f, v, pVar, len(ws)/2)
+		fmt.Fprintf(g.out, ws+"%s = %s.Member{Object: %s, Key: %q}\n", out, pe, pVar, key)
+	}
+
+	change := func(ws, op, v string) {
+		sensitive := ""
+		if tags.sensitive {
+			sensitive = ", Sensitive: true"
+		}
+		fmt.Fprintf(g.out, ws+"c := %s.FieldChange{Path: %s, Old: %s, Op: %s.%s%s}\n", pe, path, orig, pe, op, sensitive)
+		if !tags.sensitive {
+			encoder(ws, orig, "c.OldJSON")
+		}
+		if v != "" {
+			fmt.Fprintln(g.out, ws+"c.New = "+v)
+			if !tags.sensitive {
+				encoder(ws, v, "c.NewJSON")
+			}
+		}
+		fmt.Fprintln(g.out, ws+"*cs = append(*cs, c)")
+	}
+
+	fmt.Fprintln(g.out, "  if p."+PartialValidKey+"."+f.Name+" {")
+	ws := "    "
+	nested := !f.Anonymous && g.trackedNested(f) && !tags.replace && !tags.sensitive
+	if nested && f.Type.Kind() == reflect.Ptr {
+		fmt.Fprintln(g.out, "    if "+orig+" != nil && "+in+" != nil {")
+		fmt.Fprintln(g.out, "      "+g.helperName("changes", f.Type.Elem())+"("+path+", *"+orig+", *"+in+", cs)")
+		fmt.Fprintln(g.out, "    } else {")
+		ws = "      "
+	} else if nested {
+		fmt.Fprintln(g.out, "    "+g.helperName("changes", f.Type)+"("+path+", "+orig+", "+in+", cs)")
+	}
+	if !nested || f.Type.Kind() == reflect.Ptr {
+		vVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"var "+vVar+" "+g.typeString(f.Type))
+		switch {
+		case f.Anonymous && f.Type.Kind() == reflect.Ptr:
+			// Embedded fields are embedded by value in partial structs.
+			eVar := g.uniqueVarName()
+			fmt.Fprintln(g.out, ws+eVar+" := "+in)
+			fmt.Fprintln(g.out, ws+vVar+" = &"+eVar)
+		case f.Anonymous:
+			fmt.Fprintln(g.out, ws+vVar+" = "+in)
+		default:
+			g.genFromPartial(f.Type, in, vVar, len(ws)/2)
+		}
+		fmt.Fprintln(g.out, ws+"if !("+g.equalExpr(f.Type, vVar, orig, false)+") {")
+		if isNullable(f.Type) {
+			fmt.Fprintln(g.out, ws+"  if "+vVar+" == nil {")
+			change(ws+"    ", "OpNull", "")
+			fmt.Fprintln(g.out, ws+"  } else {")
+			change(ws+"    ", "OpSet", vVar)
+			fmt.Fprintln(g.out, ws+"  }")
+		} else {
+			change(ws+"  ", "OpSet", vVar)
+		}
+		fmt.Fprintln(g.out, ws+"}")
+	}
+	if nested && f.Type.Kind() == reflect.Ptr {
+		fmt.Fprintln(g.out, "    }")
+	}
+
+	fmt.Fprintln(g.out, "  } else if p."+PartialSetKey+"."+f.Name+" {")
+	if isNullable(f.Type) {
+		fmt.Fprintln(g.out, "    if "+orig+" != nil {")
+	} else {
+		fmt.Fprintln(g.out, "    if !("+g.zeroCheck(f.Type, orig)+") {")
+	}
+	change("      ", "OpNull", "")
+	fmt.Fprintln(g.out, "    }")
+	fmt.Fprintln(g.out, "  }")
+}
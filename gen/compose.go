@@ -0,0 +1,106 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Compose requests to generate ApplyPartial<T>, applying partials to structs, and
+// ComposePartial<T>, coalescing partials into one.
+func (g *PartialGenerator) Compose() {
+	g.compose = true
+}
+
+// genApply generates the function applying a partial struct of t to a t.
+func (g *PartialGenerator) genApply(t reflect.Type) {
+	sname := g.getStructName(t)
+
+	fmt.Fprintf(g.out, "// Apply%s applies the partial p to v: valid fields are written and fields set to null\n", sname)
+	fmt.Fprintln(g.out, "// are reset to nil or their zero value. Nested partials patch the structs of v unless they")
+	fmt.Fprintln(g.out, "// are tagged partial:\"replace\".")
+	fmt.Fprintln(g.out, "func Apply"+sname+"(v *"+t.Name()+", p "+sname+") {")
+	g.genApplyFields(t, "p", "v", 1)
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+}
+
+// genCompose generates the composition of partial structs of t. Applying the composition of
+// partials is the same as applying them in order.
+func (g *PartialGenerator) genCompose(t reflect.Type) {
+	sname := g.getStructName(t)
+	fname := g.helperName("compose", t)
+
+	fmt.Fprintf(g.out, "// Compose%s coalesces the partials ps into one. Later partials win per field, and\n", sname)
+	fmt.Fprintln(g.out, "// nested partials are composed as well unless they are tagged partial:\"replace\".")
+	fmt.Fprintln(g.out, "func Compose"+sname+"(ps ..."+sname+") "+sname+" {")
+	fmt.Fprintln(g.out, "  var p "+sname)
+	fmt.Fprintln(g.out, "  for _, q := range ps {")
+	fmt.Fprintln(g.out, "    p = "+fname+"(p, q)")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "  return p")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	fmt.Fprintln(g.out, "func "+fname+"(p, q "+sname+") "+sname+" {")
+	for i := 0; i < t.NumField(); i++ {
+		g.genComposeField(t.Field(i))
+	}
+	fmt.Fprintln(g.out, "  return p")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+}
+
+func (g *PartialGenerator) genComposeField(f reflect.StructField) {
+	valid := func(v string) string { return v + "." + PartialValidKey + "." + f.Name }
+	set := func(v string) string { return v + "." + PartialSetKey + "." + f.Name }
+
+	fmt.Fprintln(g.out, "  switch {")
+	fmt.Fprintln(g.out, "  case !"+valid("q")+" && !"+set("q")+":")
+
+	if !f.Anonymous && g.trackedNested(f) && !parseFieldTags(f).replace {
+		ft := f.Type
+		isPtr := ft.Kind() == reflect.Ptr
+		if isPtr {
+			ft = ft.Elem()
+		}
+
+		// Both patch the struct.
+		cond := valid("p") + " && " + valid("q")
+		if isPtr {
+			cond += " && p." + f.Name + " != nil && q." + f.Name + " != nil"
+		}
+		fmt.Fprintln(g.out, "  case "+cond+":")
+		if isPtr {
+			vVar := g.uniqueVarName()
+			fmt.Fprintln(g.out, "    "+vVar+" := "+g.helperName("compose", ft)+"(*p."+f.Name+", *q."+f.Name+")")
+			fmt.Fprintln(g.out, "    p."+f.Name+" = &"+vVar)
+		} else {
+			fmt.Fprintln(g.out, "    p."+f.Name+" = "+g.helperName("compose", ft)+"(p."+f.Name+", q."+f.Name+")")
+		}
+
+		// A patch after a reset applies to the zero value, so it is expanded into the value
+		// it makes, which replaces the struct.
+		reset := "!" + valid("p") + " && " + set("p")
+		cond = valid("q")
+		if isPtr {
+			reset = "(" + reset + " || " + valid("p") + " && p." + f.Name + " == nil)"
+			cond += " && q." + f.Name + " != nil"
+		}
+		fmt.Fprintln(g.out, "  case "+cond+" && "+reset+":")
+		if isPtr {
+			vVar := g.uniqueVarName()
+			fmt.Fprintln(g.out, "    "+vVar+" := "+g.helperName("toPartial", ft)+"("+g.helperName("fromPartial", ft)+"(*q."+f.Name+"))")
+			fmt.Fprintln(g.out, "    p."+f.Name+" = &"+vVar)
+		} else {
+			fmt.Fprintln(g.out, "    p."+f.Name+" = "+g.helperName("toPartial", ft)+"("+g.helperName("fromPartial", ft)+"(q."+f.Name+"))")
+		}
+		fmt.Fprintln(g.out, "    "+valid("p")+" = true")
+		fmt.Fprintln(g.out, "    "+set("p")+" = false")
+	}
+
+	fmt.Fprintln(g.out, "  default:")
+	fmt.Fprintln(g.out, "    p."+f.Name+" = q."+f.Name)
+	fmt.Fprintln(g.out, "    "+valid("p")+" = "+valid("q"))
+	fmt.Fprintln(g.out, "    "+set("p")+" = "+set("q"))
+	fmt.Fprintln(g.out, "  }")
+}
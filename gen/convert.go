@@ -0,0 +1,336 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Conversion of structs to their partial counterparts and back, used by the generated helpers
+// working on both, such as trackers. The partial counterpart of a value has all fields valid,
+// except for the nil ones that are set to null. A partial converts back to the value it makes
+// of a zero value when applied to it.
+
+// needsConversion returns whether any of the requested helpers converts structs.
+func (g *PartialGenerator) needsConversion() bool {
+	return g.tracked || g.compose || g.inverse || g.changes
+}
+
+func (g *PartialGenerator) uniqueVarName() string {
+	g.varCounter++
+	return fmt.Sprint("v", g.varCounter)
+}
+
+// helperName returns the name of a generated function of the given kind for the type t. The
+// file hash keeps it from clashing with functions of the package.
+func (g *PartialGenerator) helperName(kind string, t reflect.Type) string {
+	return joinFunctionNameParts(true, "partialencode", g.hashString, kind, t.Name())
+}
+
+// hasPartial returns whether partial structs are generated for the type t.
+func (g *PartialGenerator) hasPartial(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.Name() != "" && t.PkgPath() == g.pkgPath
+}
+
+// isNullable returns whether nil values of the type t are encoded as null.
+func isNullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// genToPartialFunc generates the function converting a struct t to its partial counterpart.
+func (g *PartialGenerator) genToPartialFunc(t reflect.Type) {
+	fname := g.helperName("toPartial", t)
+	sname := g.getStructName(t)
+
+	fmt.Fprintf(g.out, "// %s returns the partial counterpart of v.\n", fname)
+	fmt.Fprintln(g.out, "func "+fname+"(v "+t.Name()+") "+sname+" {")
+	fmt.Fprintln(g.out, "  var p "+sname)
+	g.genFieldsToPartial(t, "v", "p", 1)
+	fmt.Fprintln(g.out, "  return p")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+}
+
+// genFieldsToPartial generates the conversion of the fields of the struct in to the fields of
+// the partial struct out, and marks them as valid or set.
+func (g *PartialGenerator) genFieldsToPartial(t reflect.Type, in, out string, indent int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		g.genFieldToPartial(f, in+"."+f.Name, out, indent)
+	}
+}
+
+// genFieldToPartial generates the conversion of the value in of the field f to the field of
+// the partial struct out, and marks it as valid or set.
+func (g *PartialGenerator) genFieldToPartial(f reflect.StructField, in, out string, indent int) {
+	ws := strings.Repeat("  ", indent)
+	if f.Anonymous && f.Type.Kind() == reflect.Ptr {
+		// Embedded fields are embedded by value in partial structs.
+		fmt.Fprintln(g.out, ws+"if "+in+" != nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+"."+f.Name+" = *"+in)
+		fmt.Fprintln(g.out, ws+"}")
+	} else if f.Anonymous {
+		fmt.Fprintln(g.out, ws+out+"."+f.Name+" = "+in)
+	} else {
+		g.genToPartial(f.Type, in, out+"."+f.Name, indent)
+	}
+	g.genPartialFlag(f, in, out, indent)
+}
+
+// genPartialFlag generates marking the field f of the partial struct out as valid, or as set
+// if its value in is nil.
+func (g *PartialGenerator) genPartialFlag(f reflect.StructField, in, out string, indent int) {
+	ws := strings.Repeat("  ", indent)
+	if f.Anonymous || !isNullable(f.Type) {
+		fmt.Fprintln(g.out, ws+out+"."+PartialValidKey+"."+f.Name+" = true")
+		return
+	}
+	fmt.Fprintln(g.out, ws+"if "+in+" == nil {")
+	fmt.Fprintln(g.out, ws+"  "+out+"."+PartialSetKey+"."+f.Name+" = true")
+	fmt.Fprintln(g.out, ws+"} else {")
+	fmt.Fprintln(g.out, ws+"  "+out+"."+PartialValidKey+"."+f.Name+" = true")
+	fmt.Fprintln(g.out, ws+"}")
+}
+
+// genToPartial generates the assignment of the partial counterpart of in of type t to out.
+func (g *PartialGenerator) genToPartial(t reflect.Type, in, out string, indent int) {
+	ws := strings.Repeat("  ", indent)
+	if g.keepsType(t) {
+		fmt.Fprintln(g.out, ws+out+" = "+in)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		fmt.Fprintln(g.out, ws+"if "+in+" == nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = nil")
+		fmt.Fprintln(g.out, ws+"} else {")
+		fmt.Fprint(g.out, ws+"  "+out+" = new(")
+		g.genTypePartial(t.Elem(), indent+1)
+		fmt.Fprintln(g.out, ")")
+		g.genToPartial(t.Elem(), "(*"+in+")", "(*"+out+")", indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Slice:
+		iVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"if "+in+" == nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = nil")
+		fmt.Fprintln(g.out, ws+"} else {")
+		fmt.Fprint(g.out, ws+"  "+out+" = make(")
+		g.genTypePartial(t, indent+1)
+		fmt.Fprintln(g.out, ", len("+in+"))")
+		fmt.Fprintln(g.out, ws+"  for "+iVar+" := range "+in+" {")
+		g.genToPartial(t.Elem(), "("+in+")["+iVar+"]", "("+out+")["+iVar+"]", indent+2)
+		fmt.Fprintln(g.out, ws+"  }")
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Array:
+		iVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"for "+iVar+" := range "+in+" {")
+		g.genToPartial(t.Elem(), "("+in+")["+iVar+"]", "("+out+")["+iVar+"]", indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Map:
+		kVar := g.uniqueVarName()
+		eVar := g.uniqueVarName()
+		pkVar := g.uniqueVarName()
+		peVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"if "+in+" == nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = nil")
+		fmt.Fprintln(g.out, ws+"} else {")
+		fmt.Fprint(g.out, ws+"  "+out+" = make(")
+		g.genTypePartial(t, indent+1)
+		fmt.Fprintln(g.out, ", len("+in+"))")
+		fmt.Fprintln(g.out, ws+"  for "+kVar+", "+eVar+" := range "+in+" {")
+		if g.keepsType(t.Key()) {
+			pkVar = kVar
+		} else {
+			fmt.Fprint(g.out, ws+"    var "+pkVar+" ")
+			g.genTypePartial(t.Key(), indent+2)
+			fmt.Fprintln(g.out)
+			g.genToPartial(t.Key(), kVar, pkVar, indent+2)
+		}
+		fmt.Fprint(g.out, ws+"    var "+peVar+" ")
+		g.genTypePartial(t.Elem(), indent+2)
+		fmt.Fprintln(g.out)
+		g.genToPartial(t.Elem(), eVar, peVar, indent+2)
+		fmt.Fprintln(g.out, ws+"    ("+out+")["+pkVar+"] = "+peVar)
+		fmt.Fprintln(g.out, ws+"  }")
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Struct:
+		if t.Name() != "" {
+			fmt.Fprintln(g.out, ws+out+" = "+g.helperName("toPartial", t)+"("+in+")")
+			return
+		}
+		g.genFieldsToPartial(t, in, out, indent)
+
+	case reflect.Interface:
+		vVar := g.uniqueVarName()
+		pVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"switch "+vVar+" := ("+in+").(type) {")
+		for _, v := range variantsOf(g.variants, t.Name()) {
+			fmt.Fprintln(g.out, ws+"case *"+v.t.Name()+":")
+			fmt.Fprintln(g.out, ws+"  if "+vVar+" == nil {")
+			fmt.Fprintln(g.out, ws+"    "+out+" = (*"+g.getStructName(v.t)+")(nil)")
+			fmt.Fprintln(g.out, ws+"  } else {")
+			fmt.Fprintln(g.out, ws+"    "+pVar+" := "+g.helperName("toPartial", v.t)+"(*"+vVar+")")
+			fmt.Fprintln(g.out, ws+"    "+out+" = &"+pVar)
+			fmt.Fprintln(g.out, ws+"  }")
+		}
+		fmt.Fprintln(g.out, ws+"default:")
+		fmt.Fprintln(g.out, ws+"  // Only variants have partial counterparts.")
+		fmt.Fprintln(g.out, ws+"  "+out+" = nil")
+		fmt.Fprintln(g.out, ws+"}")
+	}
+}
+
+// genFromPartialFunc generates the function converting a partial struct of t back to t.
+func (g *PartialGenerator) genFromPartialFunc(t reflect.Type) {
+	fname := g.helperName("fromPartial", t)
+	sname := g.getStructName(t)
+
+	fmt.Fprintf(g.out, "// %s returns the value p makes of a zero %s.\n", fname, t.Name())
+	fmt.Fprintln(g.out, "func "+fname+"(p "+sname+") "+t.Name()+" {")
+	fmt.Fprintln(g.out, "  var v "+t.Name())
+	g.genApplyFields(t, "p", "v", 1)
+	fmt.Fprintln(g.out, "  return v")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+}
+
+// genApplyFields generates the application of the fields of the partial struct in to the
+// fields of the struct out: valid fields are written, and fields set to null are reset to nil
+// or their zero value. Nested partials patch the structs they are applied to, unless they are
+// tagged to replace them.
+func (g *PartialGenerator) genApplyFields(t reflect.Type, in, out string, indent int) {
+	ws := strings.Repeat("  ", indent)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fin, fout := in+"."+f.Name, out+"."+f.Name
+
+		fmt.Fprintln(g.out, ws+"if "+in+"."+PartialValidKey+"."+f.Name+" {")
+		switch {
+		case f.Anonymous && f.Type.Kind() == reflect.Ptr:
+			// Embedded fields are embedded by value in partial structs.
+			vVar := g.uniqueVarName()
+			fmt.Fprintln(g.out, ws+"  "+vVar+" := "+fin)
+			fmt.Fprintln(g.out, ws+"  "+fout+" = &"+vVar)
+		case f.Anonymous:
+			fmt.Fprintln(g.out, ws+"  "+fout+" = "+fin)
+		case g.trackedNested(f) && !parseFieldTags(f).replace && f.Type.Kind() == reflect.Ptr:
+			ft := f.Type.Elem()
+			fmt.Fprintln(g.out, ws+"  if "+fin+" == nil {")
+			fmt.Fprintln(g.out, ws+"    "+fout+" = nil")
+			fmt.Fprintln(g.out, ws+"  } else {")
+			fmt.Fprintln(g.out, ws+"    if "+fout+" == nil {")
+			fmt.Fprintln(g.out, ws+"      "+fout+" = new("+ft.Name()+")")
+			fmt.Fprintln(g.out, ws+"    }")
+			fmt.Fprintln(g.out, ws+"    Apply"+g.getStructName(ft)+"("+fout+", *"+fin+")")
+			fmt.Fprintln(g.out, ws+"  }")
+		case g.trackedNested(f) && !parseFieldTags(f).replace:
+			fmt.Fprintln(g.out, ws+"  Apply"+g.getStructName(f.Type)+"(&"+fout+", "+fin+")")
+		default:
+			g.genFromPartial(f.Type, fin, fout, indent+1)
+		}
+		fmt.Fprintln(g.out, ws+"} else if "+in+"."+PartialSetKey+"."+f.Name+" {")
+		if isNullable(f.Type) {
+			fmt.Fprintln(g.out, ws+"  "+fout+" = nil")
+		} else {
+			vVar := g.uniqueVarName()
+			fmt.Fprintln(g.out, ws+"  var "+vVar+" "+g.typeString(f.Type))
+			fmt.Fprintln(g.out, ws+"  "+fout+" = "+vVar)
+		}
+		fmt.Fprintln(g.out, ws+"}")
+	}
+}
+
+// genFromPartial generates the assignment of the value converted from the partial in of type t
+// to out, the reverse of genToPartial.
+func (g *PartialGenerator) genFromPartial(t reflect.Type, in, out string, indent int) {
+	ws := strings.Repeat("  ", indent)
+	if g.keepsType(t) {
+		fmt.Fprintln(g.out, ws+out+" = "+in)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		fmt.Fprintln(g.out, ws+"if "+in+" == nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = nil")
+		fmt.Fprintln(g.out, ws+"} else {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = new("+g.typeString(t.Elem())+")")
+		g.genFromPartial(t.Elem(), "(*"+in+")", "(*"+out+")", indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Slice:
+		iVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"if "+in+" == nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = nil")
+		fmt.Fprintln(g.out, ws+"} else {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = make("+g.typeString(t)+", len("+in+"))")
+		fmt.Fprintln(g.out, ws+"  for "+iVar+" := range "+in+" {")
+		g.genFromPartial(t.Elem(), "("+in+")["+iVar+"]", "("+out+")["+iVar+"]", indent+2)
+		fmt.Fprintln(g.out, ws+"  }")
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Array:
+		iVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"for "+iVar+" := range "+in+" {")
+		g.genFromPartial(t.Elem(), "("+in+")["+iVar+"]", "("+out+")["+iVar+"]", indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Map:
+		kVar := g.uniqueVarName()
+		eVar := g.uniqueVarName()
+		okVar := g.uniqueVarName()
+		oeVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"if "+in+" == nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = nil")
+		fmt.Fprintln(g.out, ws+"} else {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = make("+g.typeString(t)+", len("+in+"))")
+		fmt.Fprintln(g.out, ws+"  for "+kVar+", "+eVar+" := range "+in+" {")
+		if g.keepsType(t.Key()) {
+			okVar = kVar
+		} else {
+			fmt.Fprintln(g.out, ws+"    var "+okVar+" "+g.typeString(t.Key()))
+			g.genFromPartial(t.Key(), kVar, okVar, indent+2)
+		}
+		fmt.Fprintln(g.out, ws+"    var "+oeVar+" "+g.typeString(t.Elem()))
+		g.genFromPartial(t.Elem(), eVar, oeVar, indent+2)
+		fmt.Fprintln(g.out, ws+"    ("+out+")["+okVar+"] = "+oeVar)
+		fmt.Fprintln(g.out, ws+"  }")
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Struct:
+		if t.Name() != "" {
+			fmt.Fprintln(g.out, ws+out+" = "+g.helperName("fromPartial", t)+"("+in+")")
+			return
+		}
+		vVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"var "+vVar+" "+g.typeString(t))
+		g.genApplyFields(t, in, vVar, indent)
+		fmt.Fprintln(g.out, ws+out+" = "+vVar)
+
+	case reflect.Interface:
+		vVar := g.uniqueVarName()
+		oVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"switch "+vVar+" := ("+in+").(type) {")
+		for _, v := range variantsOf(g.variants, t.Name()) {
+			fmt.Fprintln(g.out, ws+"case *"+g.getStructName(v.t)+":")
+			fmt.Fprintln(g.out, ws+"  if "+vVar+" == nil {")
+			fmt.Fprintln(g.out, ws+"    "+out+" = (*"+v.t.Name()+")(nil)")
+			fmt.Fprintln(g.out, ws+"  } else {")
+			fmt.Fprintln(g.out, ws+"    "+oVar+" := "+g.helperName("fromPartial", v.t)+"(*"+vVar+")")
+			fmt.Fprintln(g.out, ws+"    "+out+" = &"+oVar)
+			fmt.Fprintln(g.out, ws+"  }")
+		}
+		fmt.Fprintln(g.out, ws+"default:")
+		fmt.Fprintln(g.out, ws+"  "+out+" = nil")
+		fmt.Fprintln(g.out, ws+"}")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"unicode"
 
@@ -54,12 +55,18 @@ var primitiveStringDecoders = map[reflect.Kind]string{
 
 var customDecoders = map[string]string{
 	"json.Number": "in.JsonNumber()",
+	"*big.Int":    "in.BigInt()",
+	"*big.Float":  "in.BigFloat()",
 }
 
 // genTypeDecoder generates decoding code for the type t, but uses unmarshaler interface if implemented by t.
 func (g *Generator) genTypeDecoder(t reflect.Type, out string, tags fieldTags, indent int) error {
 	ws := strings.Repeat("  ", indent)
 
+	if hasTimeFormat(t, tags) {
+		return g.genTimeDecoder(t, out, tags, indent)
+	}
+
 	unmarshalerIface := reflect.TypeOf((*partialencode.Unmarshaler)(nil)).Elem()
 	if reflect.PtrTo(t).Implements(unmarshalerIface) {
 		fmt.Fprintln(g.out, ws+"("+out+").UnMarshalPartialJSON(in)")
@@ -259,7 +266,7 @@ func (g *Generator) genTypeDecoderNoCheck(t reflect.Type, out string, tags field
 
 	case reflect.Interface:
 		if t.NumMethod() != 0 {
-			return fmt.Errorf("interface type %v not supported: only interface{} is allowed", t)
+			return g.genUnionDecoder(t, out, tags, indent)
 		}
 		fmt.Fprintln(g.out, ws+"if m, ok := "+out+".(partialencode.Unmarshaler); ok {")
 		fmt.Fprintln(g.out, ws+"m.UnMarshalPartialJSON(in)")
@@ -284,6 +291,16 @@ func (g *Generator) genStructFieldDecoder(t reflect.Type, f reflect.StructField)
 	}
 
 	fmt.Fprintf(g.out, "    case %q:\n", jsonName)
+	if !hasPartialFlags(t) {
+		if err := g.genFieldValueDecoder(t, f, "out."+f.Name, tags, 3); err != nil {
+			return err
+		}
+		if tags.required {
+			fmt.Fprintf(g.out, "%sSet = true\n", f.Name)
+		}
+		return nil
+	}
+	g.genAuthorize(tags, 3)
 	fmt.Fprintln(g.out, "       if in.IsNull() {")
 	fmt.Fprintln(g.out, "          out."+PartialSetKey+"."+f.Name+" = true")
 	fmt.Fprintln(g.out, "          in.Skip()")
@@ -291,7 +308,7 @@ func (g *Generator) genStructFieldDecoder(t reflect.Type, f reflect.StructField)
 	fmt.Fprintln(g.out, "          continue")
 	fmt.Fprintln(g.out, "       }")
 	fmt.Fprintln(g.out, "       out."+PartialValidKey+"."+f.Name+" = true")
-	if err := g.genTypeDecoder(f.Type, "out."+f.Name, tags, 3); err != nil {
+	if err := g.genFieldValueDecoder(t, f, "out."+f.Name, tags, 3); err != nil {
 		return err
 	}
 
@@ -302,6 +319,39 @@ func (g *Generator) genStructFieldDecoder(t reflect.Type, f reflect.StructField)
 	return nil
 }
 
+// genFieldValueDecoder generates code that decodes the value of the field f of the struct t
+// into out, decrypting it with the cipher of the field if it has one.
+func (g *Generator) genFieldValueDecoder(t reflect.Type, f reflect.StructField, out string, tags fieldTags, indent int) error {
+	if tags.encrypt == "" {
+		return g.genTypeDecoder(f.Type, out, tags, indent)
+	}
+	ws := strings.Repeat("  ", indent)
+	fmt.Fprintf(g.out, ws+"partialencode.DecryptField(in, %q, %q, func(in *jlexer.Lexer) {\n", tags.encrypt, g.cipherPath(t, f))
+	if err := g.genTypeDecoder(f.Type, out, tags, indent+1); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, ws+"})")
+	return nil
+}
+
+// genAuthorize generates the check that the policy of the lexer allows to write a field with
+// the write option, which skips the field otherwise, before any of its flags is set.
+func (g *Generator) genAuthorize(tags fieldTags, indent int) {
+	if len(tags.write) == 0 {
+		return
+	}
+	ws := strings.Repeat("  ", indent)
+	roles := make([]string, len(tags.write))
+	for i, r := range tags.write {
+		roles[i] = strconv.Quote(r)
+	}
+	fmt.Fprintln(g.out, ws+"if !in.Authorize("+strings.Join(roles, ", ")+") {")
+	fmt.Fprintln(g.out, ws+"  in.SkipRecursive()")
+	fmt.Fprintln(g.out, ws+"  in.WantComma()")
+	fmt.Fprintln(g.out, ws+"  continue")
+	fmt.Fprintln(g.out, ws+"}")
+}
+
 func (g *Generator) genRequiredFieldSet(t reflect.Type, f reflect.StructField) {
 	tags := parseFieldTags(f)
 
@@ -320,10 +370,8 @@ func (g *Generator) genRequiredFieldCheck(t reflect.Type, f reflect.StructField)
 		return
 	}
 
-	g.imports["fmt"] = "fmt"
-
 	fmt.Fprintf(g.out, "if !%sSet {\n", f.Name)
-	fmt.Fprintf(g.out, "    in.AddError(fmt.Errorf(\"key '%s' is required\"))\n", jsonName)
+	fmt.Fprintf(g.out, "    in.AddMissingKeyError(%q)\n", jsonName)
 	fmt.Fprintf(g.out, "}\n")
 }
 
@@ -468,13 +516,16 @@ func (g *Generator) genStructDecoder(t reflect.Type) error {
 			return err
 		}
 	}
+	if err := g.genDiscriminatorCase(t, fs); err != nil {
+		return err
+	}
 
 	fmt.Fprintln(g.out, "    default:")
 	if g.disallowUnknownFields {
 		fmt.Fprintln(g.out, `      in.AddError(&jlexer.LexerError{
           Offset: in.GetPos(),
           Reason: "unknown field",
-          Data: key,
+          Data: string([]byte(key)),
       })`)
 	} else {
 		fmt.Fprintln(g.out, "      in.SkipRecursive()")
@@ -487,6 +538,11 @@ func (g *Generator) genStructDecoder(t reflect.Type) error {
 	fmt.Fprintln(g.out, "    in.Consumed()")
 	fmt.Fprintln(g.out, "  }")
 
+	defaulterIface := reflect.TypeOf((*partialencode.Defaulter)(nil)).Elem()
+	if reflect.PtrTo(t).Implements(defaulterIface) {
+		fmt.Fprintln(g.out, "  out.ApplyDefaults()")
+	}
+
 	for _, f := range fs {
 		g.genRequiredFieldCheck(t, f)
 	}
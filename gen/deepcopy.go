@@ -0,0 +1,376 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DeepCopy requests to generate DeepCopy and Equal methods of structs, their partial structs
+// and their partial bool structs.
+func (g *PartialGenerator) DeepCopy() {
+	g.deepCopy = true
+}
+
+// needsEqual returns whether any of the requested helpers compares values with the Equal
+// methods, which are then generated without DeepCopy.
+func (g *PartialGenerator) needsEqual() bool {
+	return g.deepCopy || g.merge || g.inverse || g.changes
+}
+
+// copyMethod returns the name of the method of *t setting the receiver to a copy of its
+// argument, such as Set of big.Int and Copy of big.Float, or "" if there is none.
+func copyMethod(t reflect.Type) string {
+	pt := reflect.PtrTo(t)
+	for _, name := range []string{"Copy", "Set"} {
+		m, ok := pt.MethodByName(name)
+		if ok && m.Type.NumIn() == 2 && m.Type.In(1) == pt && m.Type.NumOut() == 1 && m.Type.Out(0) == pt {
+			return name
+		}
+	}
+	return ""
+}
+
+// The walks below go over the types of the original structs. In partial mode they generate
+// code for the partial counterparts of the types, as printed by genTypePartial.
+
+// genTypeExpr prints the type t, or its partial counterpart in partial mode.
+func (g *PartialGenerator) genTypeExpr(t reflect.Type, partial bool, indent int) {
+	if partial {
+		g.genTypePartial(t, indent)
+	} else {
+		fmt.Fprint(g.out, g.typeString(t))
+	}
+}
+
+// structTypeName returns the name of the struct t, or of its partial struct in partial mode.
+func (g *PartialGenerator) structTypeName(t reflect.Type, partial bool) string {
+	if partial {
+		return g.getStructName(t)
+	}
+	return t.Name()
+}
+
+// needsDeepCopy returns whether values of the type t share memory when assigned. Structs of
+// other packages are copied by assignment.
+func (g *PartialGenerator) needsDeepCopy(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		return true
+	case reflect.Array:
+		return g.needsDeepCopy(t.Elem())
+	case reflect.Struct:
+		if t.Name() != "" {
+			return t.PkgPath() == g.pkgPath || copyMethod(t) != ""
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if g.needsDeepCopy(t.Field(i).Type) {
+				return true
+			}
+		}
+	case reflect.Interface:
+		return t.PkgPath() == g.pkgPath && len(variantsOf(g.variants, t.Name())) > 0
+	}
+	return false
+}
+
+// genDeepCopy generates the DeepCopy methods of the struct t, its partial struct and its
+// partial bool struct.
+func (g *PartialGenerator) genDeepCopy(t reflect.Type) {
+	for _, partial := range []bool{false, true} {
+		name := g.structTypeName(t, partial)
+
+		fmt.Fprintf(g.out, "// DeepCopy returns a copy of v that shares no memory with it.\n")
+		fmt.Fprintln(g.out, "func (v "+name+") DeepCopy() "+name+" {")
+		fmt.Fprintln(g.out, "  c := v")
+		g.genFieldsCopy(t, "v", "c", partial, 1)
+		fmt.Fprintln(g.out, "  return c")
+		fmt.Fprintln(g.out, "}")
+		fmt.Fprintln(g.out)
+	}
+
+	bname := g.getBoolStructName(t)
+	fmt.Fprintf(g.out, "// DeepCopy returns a copy of v.\n")
+	fmt.Fprintln(g.out, "func (v "+bname+") DeepCopy() "+bname+" {")
+	fmt.Fprintln(g.out, "  return v")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+}
+
+// genEqualMethods generates the Equal methods of the struct t, its partial struct and its
+// partial bool struct.
+func (g *PartialGenerator) genEqualMethods(t reflect.Type) {
+	for _, partial := range []bool{false, true} {
+		name := g.structTypeName(t, partial)
+
+		if partial {
+			fmt.Fprintf(g.out, "// Equal returns whether v and o have equal values and partial flags.\n")
+		} else {
+			fmt.Fprintf(g.out, "// Equal returns whether v and o have equal values.\n")
+		}
+		fmt.Fprintln(g.out, "func (v "+name+") Equal(o "+name+") bool {")
+		g.genFieldsEqual(t, "v", "o", partial, 1)
+		fmt.Fprintln(g.out, "  return true")
+		fmt.Fprintln(g.out, "}")
+		fmt.Fprintln(g.out)
+	}
+
+	bname := g.getBoolStructName(t)
+	fmt.Fprintf(g.out, "// Equal returns whether v and o have the same flags.\n")
+	fmt.Fprintln(g.out, "func (v "+bname+") Equal(o "+bname+") bool {")
+	fmt.Fprintln(g.out, "  return v == o")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+}
+
+// genFieldsCopy generates the deep copy of the fields of the struct in to out, which is
+// assigned in already.
+func (g *PartialGenerator) genFieldsCopy(t reflect.Type, in, out string, partial bool, indent int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		ft, fpartial := f.Type, partial
+		if partial && f.Anonymous {
+			// Embedded fields are embedded by value in partial structs, see
+			// genFieldPartialStruct.
+			fpartial = false
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+		}
+		if g.needsDeepCopy(ft) {
+			g.genCopy(ft, in+"."+f.Name, out+"."+f.Name, fpartial, indent)
+		}
+	}
+}
+
+// genCopy generates the assignment of a deep copy of in of type t to out.
+func (g *PartialGenerator) genCopy(t reflect.Type, in, out string, partial bool, indent int) {
+	ws := strings.Repeat("  ", indent)
+	if !g.needsDeepCopy(t) {
+		fmt.Fprintln(g.out, ws+out+" = "+in)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		fmt.Fprintln(g.out, ws+"if "+in+" == nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = nil")
+		fmt.Fprintln(g.out, ws+"} else {")
+		if m := copyMethod(t.Elem()); m != "" && !g.hasPartial(t.Elem()) {
+			fmt.Fprintln(g.out, ws+"  "+out+" = new("+g.typeString(t.Elem())+")."+m+"("+in+")")
+			fmt.Fprintln(g.out, ws+"}")
+			return
+		}
+		fmt.Fprint(g.out, ws+"  "+out+" = new(")
+		g.genTypeExpr(t.Elem(), partial, indent+1)
+		fmt.Fprintln(g.out, ")")
+		g.genCopy(t.Elem(), "(*"+in+")", "(*"+out+")", partial, indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Slice:
+		iVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"if "+in+" == nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = nil")
+		fmt.Fprintln(g.out, ws+"} else {")
+		fmt.Fprint(g.out, ws+"  "+out+" = make(")
+		g.genTypeExpr(t, partial, indent+1)
+		fmt.Fprintln(g.out, ", len("+in+"))")
+		if g.needsDeepCopy(t.Elem()) {
+			fmt.Fprintln(g.out, ws+"  for "+iVar+" := range "+in+" {")
+			g.genCopy(t.Elem(), "("+in+")["+iVar+"]", "("+out+")["+iVar+"]", partial, indent+2)
+			fmt.Fprintln(g.out, ws+"  }")
+		} else {
+			fmt.Fprintln(g.out, ws+"  copy("+out+", "+in+")")
+		}
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Array:
+		iVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"for "+iVar+" := range "+in+" {")
+		g.genCopy(t.Elem(), "("+in+")["+iVar+"]", "("+out+")["+iVar+"]", partial, indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Map:
+		kVar := g.uniqueVarName()
+		eVar := g.uniqueVarName()
+		cVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"if "+in+" == nil {")
+		fmt.Fprintln(g.out, ws+"  "+out+" = nil")
+		fmt.Fprintln(g.out, ws+"} else {")
+		fmt.Fprint(g.out, ws+"  "+out+" = make(")
+		g.genTypeExpr(t, partial, indent+1)
+		fmt.Fprintln(g.out, ", len("+in+"))")
+		fmt.Fprintln(g.out, ws+"  for "+kVar+", "+eVar+" := range "+in+" {")
+		fmt.Fprint(g.out, ws+"    var "+cVar+" ")
+		g.genTypeExpr(t.Elem(), partial, indent+2)
+		fmt.Fprintln(g.out)
+		g.genCopy(t.Elem(), eVar, cVar, partial, indent+2)
+		fmt.Fprintln(g.out, ws+"    ("+out+")["+kVar+"] = "+cVar)
+		fmt.Fprintln(g.out, ws+"  }")
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Struct:
+		if m := copyMethod(t); m != "" && !g.hasPartial(t) {
+			// The copy is assigned to out, so that it keeps no memory of the value out held.
+			fmt.Fprintln(g.out, ws+out+" = *new("+g.typeString(t)+")."+m+"(&"+in+")")
+			return
+		}
+		if t.Name() != "" {
+			fmt.Fprintln(g.out, ws+out+" = ("+in+").DeepCopy()")
+			return
+		}
+		fmt.Fprintln(g.out, ws+out+" = "+in)
+		g.genFieldsCopy(t, in, out, partial, indent)
+
+	case reflect.Interface:
+		vVar := g.uniqueVarName()
+		cVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"switch "+vVar+" := ("+in+").(type) {")
+		for _, v := range variantsOf(g.variants, t.Name()) {
+			name := g.structTypeName(v.t, partial)
+			fmt.Fprintln(g.out, ws+"case *"+name+":")
+			fmt.Fprintln(g.out, ws+"  if "+vVar+" == nil {")
+			fmt.Fprintln(g.out, ws+"    "+out+" = (*"+name+")(nil)")
+			fmt.Fprintln(g.out, ws+"  } else {")
+			fmt.Fprintln(g.out, ws+"    "+cVar+" := "+vVar+".DeepCopy()")
+			fmt.Fprintln(g.out, ws+"    "+out+" = &"+cVar)
+			fmt.Fprintln(g.out, ws+"  }")
+		}
+		fmt.Fprintln(g.out, ws+"default:")
+		fmt.Fprintln(g.out, ws+"  // Values of other types are not known to share memory.")
+		fmt.Fprintln(g.out, ws+"  "+out+" = "+in)
+		fmt.Fprintln(g.out, ws+"}")
+	}
+}
+
+// genFieldsEqual generates the comparison of the fields of the structs a and b, returning false
+// if they differ. Partial flags are compared in partial mode.
+func (g *PartialGenerator) genFieldsEqual(t reflect.Type, a, b string, partial bool, indent int) {
+	ws := strings.Repeat("  ", indent)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		ft, fpartial := f.Type, partial
+		if partial && f.Anonymous {
+			fpartial = false
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+		}
+		g.genEqual(ft, a+"."+f.Name, b+"."+f.Name, fpartial, indent)
+	}
+	if partial {
+		fmt.Fprintln(g.out, ws+"if "+a+"."+PartialValidKey+" != "+b+"."+PartialValidKey+" || "+a+"."+PartialSetKey+" != "+b+"."+PartialSetKey+" {")
+		fmt.Fprintln(g.out, ws+"  return false")
+		fmt.Fprintln(g.out, ws+"}")
+	}
+}
+
+// hasEqualMethod returns whether the type t has an Equal(t) bool method, such as time.Time.
+func hasEqualMethod(t reflect.Type) bool {
+	m, ok := t.MethodByName("Equal")
+	return ok && m.Type.NumIn() == 2 && m.Type.In(1) == t &&
+		m.Type.NumOut() == 1 && m.Type.Out(0).Kind() == reflect.Bool
+}
+
+// hasCmpMethod returns whether the type *t has a Cmp(*t) int method, such as big.Int.
+func hasCmpMethod(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	m, ok := pt.MethodByName("Cmp")
+	return ok && m.Type.NumIn() == 2 && m.Type.In(1) == pt &&
+		m.Type.NumOut() == 1 && m.Type.Out(0).Kind() == reflect.Int
+}
+
+// genEqual generates the comparison of a and b of type t, returning false if they differ.
+// Values compare as with reflect.DeepEqual, except that types with an Equal method, such as
+// time.Time, are compared with it, and types with a Cmp method, such as big.Int, by it.
+func (g *PartialGenerator) genEqual(t reflect.Type, a, b string, partial bool, indent int) {
+	ws := strings.Repeat("  ", indent)
+	notEqual := func(cond string) {
+		fmt.Fprintln(g.out, ws+"if "+cond+" {")
+		fmt.Fprintln(g.out, ws+"  return false")
+		fmt.Fprintln(g.out, ws+"}")
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		notEqual("(" + a + " == nil) != (" + b + " == nil)")
+		fmt.Fprintln(g.out, ws+"if "+a+" != nil {")
+		g.genEqual(t.Elem(), "(*"+a+")", "(*"+b+")", partial, indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Slice:
+		iVar := g.uniqueVarName()
+		notEqual("(" + a + " == nil) != (" + b + " == nil) || len(" + a + ") != len(" + b + ")")
+		fmt.Fprintln(g.out, ws+"for "+iVar+" := range "+a+" {")
+		g.genEqual(t.Elem(), "("+a+")["+iVar+"]", "("+b+")["+iVar+"]", partial, indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Array:
+		iVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"for "+iVar+" := range "+a+" {")
+		g.genEqual(t.Elem(), "("+a+")["+iVar+"]", "("+b+")["+iVar+"]", partial, indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Map:
+		kVar := g.uniqueVarName()
+		aVar := g.uniqueVarName()
+		bVar := g.uniqueVarName()
+		okVar := g.uniqueVarName()
+		notEqual("(" + a + " == nil) != (" + b + " == nil) || len(" + a + ") != len(" + b + ")")
+		fmt.Fprintln(g.out, ws+"for "+kVar+", "+aVar+" := range "+a+" {")
+		fmt.Fprintln(g.out, ws+"  "+bVar+", "+okVar+" := ("+b+")["+kVar+"]")
+		fmt.Fprintln(g.out, ws+"  if !"+okVar+" {")
+		fmt.Fprintln(g.out, ws+"    return false")
+		fmt.Fprintln(g.out, ws+"  }")
+		g.genEqual(t.Elem(), aVar, bVar, partial, indent+1)
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Struct:
+		switch {
+		case t.Name() != "" && t.PkgPath() == g.pkgPath:
+			notEqual("!(" + a + ").Equal(" + b + ")")
+		case t.Name() == "":
+			g.genFieldsEqual(t, a, b, partial, indent)
+		case hasEqualMethod(t):
+			notEqual("!(" + a + ").Equal(" + b + ")")
+		case hasCmpMethod(t):
+			notEqual("(&" + a + ").Cmp(&" + b + ") != 0")
+		case t.Comparable():
+			notEqual(a + " != " + b)
+		default:
+			notEqual("!" + g.pkgAlias("reflect") + ".DeepEqual(" + a + ", " + b + ")")
+		}
+
+	case reflect.Interface:
+		if !g.needsDeepCopy(t) {
+			notEqual("!" + g.pkgAlias("reflect") + ".DeepEqual(" + a + ", " + b + ")")
+			return
+		}
+		vVar := g.uniqueVarName()
+		wVar := g.uniqueVarName()
+		okVar := g.uniqueVarName()
+		fmt.Fprintln(g.out, ws+"switch "+vVar+" := ("+a+").(type) {")
+		for _, v := range variantsOf(g.variants, t.Name()) {
+			fmt.Fprintln(g.out, ws+"case *"+g.structTypeName(v.t, partial)+":")
+			fmt.Fprintln(g.out, ws+"  "+wVar+", "+okVar+" := ("+b+").(*"+g.structTypeName(v.t, partial)+")")
+			fmt.Fprintln(g.out, ws+"  if !"+okVar+" || ("+vVar+" == nil) != ("+wVar+" == nil) || "+vVar+" != nil && !"+vVar+".Equal(*"+wVar+") {")
+			fmt.Fprintln(g.out, ws+"    return false")
+			fmt.Fprintln(g.out, ws+"  }")
+		}
+		fmt.Fprintln(g.out, ws+"default:")
+		fmt.Fprintln(g.out, ws+"  if !"+g.pkgAlias("reflect")+".DeepEqual("+a+", "+b+") {")
+		fmt.Fprintln(g.out, ws+"    return false")
+		fmt.Fprintln(g.out, ws+"  }")
+		fmt.Fprintln(g.out, ws+"}")
+
+	case reflect.Func:
+		notEqual(a + " != nil || " + b + " != nil")
+
+	default:
+		if hasEqualMethod(t) {
+			notEqual("!(" + a + ").Equal(" + b + ")")
+		} else {
+			notEqual(a + " != " + b)
+		}
+	}
+}
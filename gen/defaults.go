@@ -0,0 +1,187 @@
+package gen
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// AddDefaultsHook registers a method of the type of the given object that sets default values
+// of its fields. ApplyDefaults of the partial struct calls it on a zero value and takes the
+// values of the fields that have no default tag from it.
+func (g *PartialGenerator) AddDefaultsHook(obj interface{}, method string) {
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if g.defaultsHooks == nil {
+		g.defaultsHooks = make(map[reflect.Type]string)
+	}
+	g.defaultsHooks[t] = method
+}
+
+// genDefaults generates the ApplyDefaults method of the partial struct of t if any of its
+// fields has a default tag or the type has a defaults hook. Defaults are parsed here, so an
+// invalid one fails generation.
+func (g *PartialGenerator) genDefaults(t reflect.Type) error {
+	hook := g.defaultsHooks[t]
+
+	type fieldDefault struct {
+		f       reflect.StructField
+		literal string
+	}
+	var defaults []fieldDefault
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		s, ok := f.Tag.Lookup("default")
+		if !ok || f.Anonymous {
+			continue
+		}
+		literal, err := defaultLiteral(f.Type, s)
+		if err != nil {
+			return fmt.Errorf("invalid default %q of field %v of %v: %v", s, f.Name, t, err)
+		}
+		defaults = append(defaults, fieldDefault{f: f, literal: literal})
+	}
+	if len(defaults) == 0 && hook == "" {
+		return nil
+	}
+
+	fmt.Fprintln(g.out, "// ApplyDefaults sets the fields that are neither valid nor set to their defaults, leaving")
+	fmt.Fprintln(g.out, "// the partial flags as they are. It supports partialencode.Defaulter interface.")
+	fmt.Fprintln(g.out, "func (v *"+g.getStructName(t)+") ApplyDefaults() {")
+	if hook != "" {
+		fmt.Fprintln(g.out, "  var d "+t.Name())
+		fmt.Fprintln(g.out, "  d."+hook+"()")
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			continue
+		}
+
+		var d *fieldDefault
+		for j := range defaults {
+			if defaults[j].f.Name == f.Name {
+				d = &defaults[j]
+			}
+		}
+		if d == nil && (hook == "" || !g.keepsType(f.Type)) {
+			continue
+		}
+
+		fmt.Fprintln(g.out, "  if !v."+PartialValidKey+"."+f.Name+" && !v."+PartialSetKey+"."+f.Name+" {")
+		if d == nil {
+			fmt.Fprintln(g.out, "    v."+f.Name+" = d."+f.Name)
+		} else {
+			g.genDefaultAssign(f.Type, "v."+f.Name, d.literal)
+		}
+		fmt.Fprintln(g.out, "  }")
+	}
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+	return nil
+}
+
+// keepsType returns whether the partial counterpart of t is t itself, so that values can be
+// copied from the original struct.
+func (g *PartialGenerator) keepsType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return g.keepsType(t.Elem())
+	case reflect.Map:
+		return g.keepsType(t.Key()) && g.keepsType(t.Elem())
+	case reflect.Struct:
+		return t.Name() != "" && t.PkgPath() != g.pkgPath
+	case reflect.Interface:
+		return t.PkgPath() != g.pkgPath || len(variantsOf(g.variants, t.Name())) == 0
+	}
+	return true
+}
+
+// genDefaultAssign generates the assignment of the default literal to out of type t.
+func (g *PartialGenerator) genDefaultAssign(t reflect.Type, out, literal string) {
+	switch {
+	case t.Kind() == reflect.Ptr:
+		fmt.Fprint(g.out, "    "+out+" = new(")
+		g.genTypePartial(t.Elem(), 0)
+		fmt.Fprintln(g.out, ")")
+		g.genDefaultAssign(t.Elem(), "*"+out, literal)
+	case t.Kind() == reflect.Struct:
+		// Optional types, see optionalValue.
+		fmt.Fprint(g.out, "    "+out+" = ")
+		g.genTypePartial(t, 0)
+		fmt.Fprintln(g.out, "{V: "+literal+", Defined: true}")
+	default:
+		fmt.Fprintln(g.out, "    "+out+" = "+literal)
+	}
+}
+
+// optionalValue returns the type of the value of an optional type such as opt.Int: a struct
+// with the fields V and Defined.
+func optionalValue(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 {
+		return nil, false
+	}
+	v, ok := t.FieldByName("V")
+	if !ok {
+		return nil, false
+	}
+	if defined, ok := t.FieldByName("Defined"); !ok || defined.Type.Kind() != reflect.Bool {
+		return nil, false
+	}
+	return v.Type, true
+}
+
+// defaultLiteral parses the default s of a field of type t and returns it as a Go literal.
+// Durations are given as for time.ParseDuration.
+func defaultLiteral(t reflect.Type, s string) (string, error) {
+	if t.Kind() == reflect.Ptr {
+		return defaultLiteral(t.Elem(), s)
+	}
+	if v, ok := optionalValue(t); ok {
+		return defaultLiteral(v, s)
+	}
+	if t == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(int64(d), 10), nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return strconv.Quote(s), nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatBool(b), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(n, 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatUint(n, 10), nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return "", err
+		}
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return "", fmt.Errorf("%v is not a valid value", f)
+		}
+		return strconv.FormatFloat(f, 'g', -1, t.Bits()), nil
+	}
+	return "", fmt.Errorf("defaults are not supported for type %v", t)
+}
@@ -12,9 +12,16 @@ import (
 )
 
 func (g *Generator) getEncoderName(t reflect.Type) string {
+	if g.fullEncoding {
+		return g.getFullEncoderName(t)
+	}
 	return g.functionName("encode", t)
 }
 
+func (g *Generator) getFullEncoderName(t reflect.Type) string {
+	return g.functionName("encodeFull", t)
+}
+
 var primitiveEncoders = map[reflect.Kind]string{
 	reflect.String:  "out.String(string(%v))",
 	reflect.Bool:    "out.Bool(bool(%v))",
@@ -49,6 +56,14 @@ var primitiveStringEncoders = map[reflect.Kind]string{
 	reflect.Float64: "out.Float64Str(float64(%v))",
 }
 
+// customEncoders contains encoders for types that are not encoded by their kind, keyed by the
+// type name. They take precedence over the encoders of the kinds.
+var customEncoders = map[string]string{
+	"json.Number": "out.JsonNumber(%v)",
+	"*big.Int":    "out.BigInt(%v)",
+	"*big.Float":  "out.BigFloat(%v)",
+}
+
 // fieldTags contains parsed version of json struct field tags.
 type fieldTags struct {
 	name string
@@ -59,6 +74,16 @@ type fieldTags struct {
 	asString    bool
 	required    bool
 	shownull    bool
+
+	// Options of the partial tag.
+	timeFormat     string
+	durationFormat string
+	union          string // Discriminator member of an interface field.
+	replace        bool   // Nested partials replace the value rather than patch it.
+	sensitive      bool   // Values are redacted by writers that request it and masked in changes.
+	views          []string
+	write          []string // Roles that may write the field when decoding partials.
+	encrypt        string   // Name of the cipher encrypting the values, see partialencode.FieldCipher.
 }
 
 // parseFieldTags parses the json field tag into a structure.
@@ -84,6 +109,41 @@ func parseFieldTags(f reflect.StructField) fieldTags {
 		}
 	}
 
+	// Options of the partial tag are separated by semicolons, since time layouts may contain
+	// commas.
+	for _, s := range strings.Split(f.Tag.Get("partial"), ";") {
+		switch strings.TrimSpace(s) {
+		case "replace":
+			ret.replace = true
+			continue
+		case "sensitive":
+			ret.sensitive = true
+			continue
+		}
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch strings.TrimSpace(kv[0]) {
+		case "time":
+			ret.timeFormat = kv[1]
+		case "duration":
+			ret.durationFormat = kv[1]
+		case "union":
+			ret.union = kv[1]
+		case "view":
+			for _, v := range strings.Split(kv[1], ",") {
+				ret.views = append(ret.views, strings.TrimSpace(v))
+			}
+		case "encrypt":
+			ret.encrypt = strings.TrimSpace(kv[1])
+		case "write":
+			for _, r := range strings.Split(kv[1], "|") {
+				ret.write = append(ret.write, strings.TrimSpace(r))
+			}
+		}
+	}
+
 	return ret
 }
 
@@ -91,6 +151,16 @@ func parseFieldTags(f reflect.StructField) fieldTags {
 func (g *Generator) genTypeEncoder(t reflect.Type, in string, tags fieldTags, indent int) error {
 	ws := strings.Repeat("  ", indent)
 
+	if hasTimeFormat(t, tags) {
+		return g.genTimeEncoder(t, in, tags, indent)
+	}
+
+	fullMarshalerIface := reflect.TypeOf((*partialencode.FullMarshaler)(nil)).Elem()
+	if g.fullEncoding && reflect.PtrTo(t).Implements(fullMarshalerIface) {
+		fmt.Fprintln(g.out, ws+"("+in+").MarshalFullJSON(out)")
+		return nil
+	}
+
 	marshalerIface := reflect.TypeOf((*partialencode.Marshaler)(nil)).Elem()
 	if reflect.PtrTo(t).Implements(marshalerIface) {
 		fmt.Fprintln(g.out, ws+"("+in+").MarshalPartialJSON(out)")
@@ -126,7 +196,10 @@ func (g *Generator) genTypeEncoderNoCheck(t reflect.Type, in string, tags fieldT
 	ws := strings.Repeat("  ", indent)
 
 	// Check whether type is primitive, needs to be done after interface check.
-	if enc := primitiveStringEncoders[t.Kind()]; enc != "" && tags.asString {
+	if enc := customEncoders[t.String()]; enc != "" {
+		fmt.Fprintf(g.out, ws+enc+"\n", in)
+		return nil
+	} else if enc := primitiveStringEncoders[t.Kind()]; enc != "" && tags.asString {
 		fmt.Fprintf(g.out, ws+enc+"\n", in)
 		return nil
 	} else if enc := primitiveEncoders[t.Kind()]; enc != "" {
@@ -187,9 +260,13 @@ func (g *Generator) genTypeEncoderNoCheck(t reflect.Type, in string, tags fieldT
 		fmt.Fprintln(g.out, ws+enc+"(out, "+in+")")
 
 	case reflect.Ptr:
+		fmt.Fprintln(g.out, ws+"if "+in+" == nil {")
+		fmt.Fprintln(g.out, ws+`  out.RawString("null")`)
+		fmt.Fprintln(g.out, ws+"} else {")
 		if err := g.genTypeEncoder(t.Elem(), "*"+in, tags, indent+1); err != nil {
 			return err
 		}
+		fmt.Fprintln(g.out, ws+"}")
 
 	case reflect.Map:
 		key := t.Key()
@@ -224,7 +301,7 @@ func (g *Generator) genTypeEncoderNoCheck(t reflect.Type, in string, tags fieldT
 
 	case reflect.Interface:
 		if t.NumMethod() != 0 {
-			return fmt.Errorf("interface type %v not supported: only interface{} is allowed", t)
+			return g.genUnionEncoder(t, in, tags, indent)
 		}
 		fmt.Fprintln(g.out, ws+"if m, ok := "+in+".(partialencode.Marshaler); ok {")
 		fmt.Fprintln(g.out, ws+"  m.MarshalPartialJSON(out)")
@@ -316,9 +393,7 @@ func (g *Generator) genStructEncoder(t reflect.Type) error {
 	typ := g.getType(t)
 
 	fmt.Fprintln(g.out, "func "+fname+"(out *jwriter.Writer, in "+typ+") {")
-	fmt.Fprintln(g.out, "  out.RawByte('{')")
-	fmt.Fprintln(g.out, "  first := true")
-	fmt.Fprintln(g.out, "  _ = first")
+	g.genObjectStart(t, "  ")
 
 	fs, err := getStructFields(t)
 	if err != nil {
@@ -338,20 +413,31 @@ func (g *Generator) genStructEncoder(t reflect.Type) error {
 			continue
 		}
 
+		g.genFilterStart(tags)
+		if g.fullEncoding || !hasPartialFlags(t) {
+			if err := g.genStructFieldFullEncoder(t, f, tags); err != nil {
+				return err
+			}
+			g.genFilterEnd(tags)
+			continue
+		}
+
 		fmt.Fprintln(g.out, "  if in."+PartialValidKey+"."+f.Name+" {")
 		if err := g.genStructFieldEncoder(t, f); err != nil {
 			return err
 		}
-		if err := g.genTypeEncoder(f.Type, "in."+f.Name, tags, 2); err != nil {
+		if err := g.genFieldValueEncoder(t, f, "in."+f.Name, tags, 2); err != nil {
 			return err
 		}
-		if tags.shownull {
+		if tags.shownull || g.unsetNull {
 			fmt.Fprintln(g.out, "  } else if in."+PartialSetKey+"."+f.Name+" {")
-			fmt.Fprintln(g.out, `out.RawString("null")`)
-
+			if err := g.genStructFieldEncoder(t, f); err != nil {
+				return err
+			}
+			fmt.Fprintln(g.out, `    out.RawString("null")`)
 		}
 		fmt.Fprintln(g.out, "  }")
-
+		g.genFilterEnd(tags)
 	}
 
 	fmt.Fprintln(g.out, "  out.RawByte('}')")
@@ -360,6 +446,128 @@ func (g *Generator) genStructEncoder(t reflect.Type) error {
 	return nil
 }
 
+// genFilterStart opens the block writing a field that the writer may skip: a field tagged with
+// views is skipped unless the view of the writer is one of them, and a sensitive field if the
+// writer omits sensitive fields.
+func (g *Generator) genFilterStart(tags fieldTags) {
+	var conds []string
+	if len(tags.views) != 0 {
+		views := make([]string, len(tags.views))
+		for i, v := range tags.views {
+			views[i] = strconv.Quote(v)
+		}
+		conds = append(conds, "out.InView("+strings.Join(views, ", ")+")")
+	}
+	if tags.sensitive {
+		conds = append(conds, "!out.OmitsSensitive()")
+	}
+	if len(conds) != 0 {
+		fmt.Fprintln(g.out, "  if "+strings.Join(conds, " && ")+" {")
+	}
+}
+
+// genFilterEnd closes the block opened by genFilterStart.
+func (g *Generator) genFilterEnd(tags fieldTags) {
+	if len(tags.views) != 0 || tags.sensitive {
+		fmt.Fprintln(g.out, "  }")
+	}
+}
+
+// genFieldValueEncoder generates code that encodes the value in of the field f of the struct t,
+// which is encrypted if the field has a cipher and redacted as the writer requests if the field
+// is sensitive. Redacted values are not encrypted, so that their hashes are deterministic.
+func (g *Generator) genFieldValueEncoder(t reflect.Type, f reflect.StructField, in string, tags fieldTags, indent int) error {
+	ws := strings.Repeat("  ", indent)
+	if !tags.sensitive {
+		return g.genEncryptedEncoder(t, f, in, tags, indent)
+	}
+	fmt.Fprintln(g.out, ws+"if out.RedactSensitive != jwriter.RedactNone {")
+	fmt.Fprintln(g.out, ws+"  out.Redacted(func(out *jwriter.Writer) {")
+	if err := g.genTypeEncoder(f.Type, in, tags, indent+2); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, ws+"  })")
+	fmt.Fprintln(g.out, ws+"} else {")
+	if err := g.genEncryptedEncoder(t, f, in, tags, indent+1); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, ws+"}")
+	return nil
+}
+
+// genEncryptedEncoder generates code that encodes the value in of the field f of the struct t,
+// encrypted with the cipher of the field if it has one.
+func (g *Generator) genEncryptedEncoder(t reflect.Type, f reflect.StructField, in string, tags fieldTags, indent int) error {
+	if tags.encrypt == "" {
+		return g.genTypeEncoder(f.Type, in, tags, indent)
+	}
+	ws := strings.Repeat("  ", indent)
+	fmt.Fprintf(g.out, ws+"partialencode.EncryptField(out, %q, %q, func(out *jwriter.Writer) {\n", tags.encrypt, g.cipherPath(t, f))
+	if err := g.genTypeEncoder(f.Type, in, tags, indent+1); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, ws+"})")
+	return nil
+}
+
+// cipherPath returns the path of the field f of the struct t passed to its cipher: the name of
+// the type the struct is the partial of and the JSON name of the field, e.g. User.ssn.
+func (g *Generator) cipherPath(t reflect.Type, f reflect.StructField) string {
+	return modelName(t) + "." + g.fieldNamer.GetJSONFieldName(t, f)
+}
+
+// modelName returns the name of the type the partial struct t was generated from, as recorded
+// by the partial generator in the tag of its PartialValid field, or the name of t otherwise.
+func modelName(t reflect.Type) string {
+	if f, ok := t.FieldByName(PartialValidKey); ok {
+		if name := strings.TrimPrefix(f.Tag.Get("partial"), PartialOfTag+"="); name != f.Tag.Get("partial") {
+			return name
+		}
+	}
+	return t.Name()
+}
+
+// hasPartialFlags returns whether t is a partial struct, which records the fields that were
+// decoded.
+func hasPartialFlags(t reflect.Type) bool {
+	_, ok := t.FieldByName(PartialValidKey)
+	return ok
+}
+
+// genStructFieldFullEncoder generates the encoding of the field f regardless of whether it is
+// valid. Fields that are set but not valid are written as null, and empty ones are omitted as
+// with encoding/json if the field is omitempty.
+func (g *Generator) genStructFieldFullEncoder(t reflect.Type, f reflect.StructField, tags fieldTags) error {
+	omitEmpty := (tags.omitEmpty || g.omitEmpty) && !tags.noOmitEmpty
+	in := "in." + f.Name
+
+	switch {
+	case hasPartialFlags(t):
+		fmt.Fprintln(g.out, "  if in."+PartialSetKey+"."+f.Name+" && !in."+PartialValidKey+"."+f.Name+" {")
+		if err := g.genStructFieldEncoder(t, f); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, `    out.RawString("null")`)
+		if omitEmpty {
+			fmt.Fprintln(g.out, "  } else if "+g.notEmptyCheck(f.Type, in)+" {")
+		} else {
+			fmt.Fprintln(g.out, "  } else {")
+		}
+	case omitEmpty:
+		fmt.Fprintln(g.out, "  if "+g.notEmptyCheck(f.Type, in)+" {")
+	default:
+		fmt.Fprintln(g.out, "  {")
+	}
+	if err := g.genStructFieldEncoder(t, f); err != nil {
+		return err
+	}
+	if err := g.genFieldValueEncoder(t, f, in, tags, 2); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, "  }")
+	return nil
+}
+
 func (g *Generator) genStructMarshaler(t reflect.Type) error {
 	switch t.Kind() {
 	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
@@ -384,5 +592,12 @@ func (g *Generator) genStructMarshaler(t reflect.Type) error {
 	fmt.Fprintln(g.out, "  "+fname+"(w, v)")
 	fmt.Fprintln(g.out, "}")
 
+	if g.fullMarshalers {
+		fmt.Fprintln(g.out, "// MarshalFullJSON supports partialencode.FullMarshaler interface")
+		fmt.Fprintln(g.out, "func (v "+typ+") MarshalFullJSON(w *jwriter.Writer) {")
+		fmt.Fprintln(g.out, "  "+g.getFullEncoderName(t)+"(w, v)")
+		fmt.Fprintln(g.out, "}")
+	}
+
 	return nil
 }
@@ -0,0 +1,167 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// enum is a named string or integer type with a list of declared constants.
+type enum struct {
+	t        reflect.Type
+	names    []string // Names of the constants, in declaration order.
+	fallback string   // Constant that unknown values are decoded to, if set.
+}
+
+// AddEnum requests to generate validation and encoding methods for the enum type of the given
+// object. The names are the constants of the type; values that do not match any of them are
+// decoded to the fallback constant if it is set, and reported as errors otherwise.
+func (g *PartialGenerator) AddEnum(obj interface{}, fallback string, names ...string) {
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	g.enums = append(g.enums, enum{t: t, names: names, fallback: fallback})
+}
+
+// integerKinds maps the kinds of integer enums to whether they are unsigned.
+var integerKinds = map[reflect.Kind]bool{
+	reflect.Int:    false,
+	reflect.Int8:   false,
+	reflect.Int16:  false,
+	reflect.Int32:  false,
+	reflect.Int64:  false,
+	reflect.Uint:   true,
+	reflect.Uint8:  true,
+	reflect.Uint16: true,
+	reflect.Uint32: true,
+	reflect.Uint64: true,
+}
+
+// genEnum generates the Values, IsValid and marshaling methods of an enum type. String enums
+// are encoded by their values and integer enums by the names of their constants. Conditions
+// are chained rather than switched on, since constants of an enum may share a value.
+func (g *PartialGenerator) genEnum(e enum) error {
+	t := e.t
+	unsigned, integer := integerKinds[t.Kind()]
+	if t.Kind() != reflect.String && !integer {
+		return fmt.Errorf("cannot generate enum for %v, it is not a string or integer type", t)
+	}
+	if len(e.names) == 0 {
+		return fmt.Errorf("cannot generate enum for %v, no constants of the type are declared", t)
+	}
+
+	name := t.Name()
+	lexer := g.pkgAlias(pkgLexer)
+	writer := g.pkgAlias(pkgWriter)
+
+	fmt.Fprintf(g.out, "// Values returns the declared values of %s.\n", name)
+	fmt.Fprintln(g.out, "func ("+name+") Values() []"+name+" {")
+	fmt.Fprintln(g.out, "  return []"+name+"{"+strings.Join(e.names, ", ")+"}")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	fmt.Fprintf(g.out, "// IsValid returns whether v is one of the declared values of %s.\n", name)
+	fmt.Fprintln(g.out, "func (v "+name+") IsValid() bool {")
+	fmt.Fprintln(g.out, "  return v == "+strings.Join(e.names, " || v == "))
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	fmt.Fprintln(g.out, "// MarshalPartialJSON supports partialencode.Marshaler interface")
+	fmt.Fprintln(g.out, "func (v "+name+") MarshalPartialJSON(out *"+writer+".Writer) {")
+	if !integer {
+		fmt.Fprintln(g.out, "  out.String(string(v))")
+	} else {
+		for i, c := range e.names {
+			if i == 0 {
+				fmt.Fprintln(g.out, "  if v == "+c+" {")
+			} else {
+				fmt.Fprintln(g.out, "  } else if v == "+c+" {")
+			}
+			fmt.Fprintf(g.out, "    out.RawString(`%q`)\n", c)
+		}
+		fmt.Fprintln(g.out, "  } else {")
+		fmt.Fprintln(g.out, "    // Values without a name are written as numbers.")
+		if unsigned {
+			fmt.Fprintln(g.out, "    out.Uint64(uint64(v))")
+		} else {
+			fmt.Fprintln(g.out, "    out.Int64(int64(v))")
+		}
+		fmt.Fprintln(g.out, "  }")
+	}
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	fmt.Fprintln(g.out, "// UnMarshalPartialJSON supports partialencode.Unmarshaler interface")
+	fmt.Fprintln(g.out, "func (v *"+name+") UnMarshalPartialJSON(in *"+lexer+".Lexer) {")
+	fmt.Fprintln(g.out, "  if in.IsNull() {")
+	fmt.Fprintln(g.out, "    in.Skip()")
+	fmt.Fprintln(g.out, "    return")
+	fmt.Fprintln(g.out, "  }")
+	if !integer {
+		fmt.Fprintln(g.out, "  s := in.UnsafeString()")
+		for i, c := range e.names {
+			if i == 0 {
+				fmt.Fprintln(g.out, "  if s == string("+c+") {")
+			} else {
+				fmt.Fprintln(g.out, "  } else if s == string("+c+") {")
+			}
+			fmt.Fprintln(g.out, "    *v = "+c)
+		}
+	} else {
+		// Numbers are accepted as well, e.g. for data written before the type became an enum.
+		fmt.Fprintln(g.out, "  if in.PeekKind() == "+lexer+".Number {")
+		if unsigned {
+			fmt.Fprintln(g.out, "    *v = "+name+"(in.Uint64())")
+		} else {
+			fmt.Fprintln(g.out, "    *v = "+name+"(in.Int64())")
+		}
+		fmt.Fprintln(g.out, "    if !v.IsValid() {")
+		g.genEnumUnknown(e, "      ")
+		fmt.Fprintln(g.out, "    }")
+		fmt.Fprintln(g.out, "    return")
+		fmt.Fprintln(g.out, "  }")
+		fmt.Fprintln(g.out, "  s := in.UnsafeString()")
+		for i, c := range e.names {
+			if i == 0 {
+				fmt.Fprintf(g.out, "  if s == %q {\n", c)
+			} else {
+				fmt.Fprintf(g.out, "  } else if s == %q {\n", c)
+			}
+			fmt.Fprintln(g.out, "    *v = "+c)
+		}
+	}
+	fmt.Fprintln(g.out, "  } else if in.Ok() {")
+	g.genEnumUnknown(e, "    ")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	if !g.noStdMarshalers {
+		fmt.Fprintln(g.out, "// MarshalJSON supports json.Marshaler interface")
+		fmt.Fprintln(g.out, "func (v "+name+") MarshalJSON() ([]byte, error) {")
+		fmt.Fprintln(g.out, "  w := "+writer+".Writer{}")
+		fmt.Fprintln(g.out, "  v.MarshalPartialJSON(&w)")
+		fmt.Fprintln(g.out, "  return w.Buffer.BuildBytes(), w.Error")
+		fmt.Fprintln(g.out, "}")
+		fmt.Fprintln(g.out)
+
+		fmt.Fprintln(g.out, "// UnmarshalJSON supports json.Unmarshaler interface")
+		fmt.Fprintln(g.out, "func (v *"+name+") UnmarshalJSON(data []byte) error {")
+		fmt.Fprintln(g.out, "  r := "+lexer+".Lexer{Data: data}")
+		fmt.Fprintln(g.out, "  v.UnMarshalPartialJSON(&r)")
+		fmt.Fprintln(g.out, "  return r.Error()")
+		fmt.Fprintln(g.out, "}")
+		fmt.Fprintln(g.out)
+	}
+	return nil
+}
+
+// genEnumUnknown generates the handling of a decoded value that is not declared.
+func (g *PartialGenerator) genEnumUnknown(e enum, ws string) {
+	if e.fallback != "" {
+		fmt.Fprintln(g.out, ws+"*v = "+e.fallback)
+		return
+	}
+	fmt.Fprintf(g.out, ws+"in.AddNonFatalError(%s.New(%q))\n", g.pkgAlias("errors"), "unknown "+e.t.Name()+" value")
+}
@@ -36,14 +36,23 @@ type Generator struct {
 	noStdMarshalers       bool
 	omitEmpty             bool
 	disallowUnknownFields bool
+	lazyPartials          bool
+	unsetNull             bool
+	fullMarshalers        bool
 	fieldNamer            FieldNamer
 
+	// whether the encoders being generated write all fields, see genStructFullEncoder
+	fullEncoding bool
+
 	// package path to local alias map for tracking imports
 	imports map[string]string
 
 	// types that marshalers were requested for by user
 	marshalers map[reflect.Type]bool
 
+	// types registered as variants of interfaces
+	variants []variant
+
 	// types that encoders were already generated for
 	typesSeen map[reflect.Type]bool
 
@@ -116,6 +125,23 @@ func (g *Generator) DisallowUnknownFields() {
 	g.disallowUnknownFields = true
 }
 
+// LazyPartials instructs to generate LazyPartial types that decode fields on first access.
+func (g *Generator) LazyPartials() {
+	g.lazyPartials = true
+}
+
+// UnsetNull instructs to write fields that are set but not valid as null, as the shownull tag
+// does for a single field. By default they are omitted.
+func (g *Generator) UnsetNull() {
+	g.unsetNull = true
+}
+
+// FullMarshalers instructs to generate MarshalFullJSON methods that write all fields of the
+// structs, regardless of which of them are valid.
+func (g *Generator) FullMarshalers() {
+	g.fullMarshalers = true
+}
+
 // OmitEmpty triggers `json=",omitempty"` behaviour by default.
 func (g *Generator) OmitEmpty() {
 	g.omitEmpty = true
@@ -197,6 +223,14 @@ func (g *Generator) Run(out io.Writer) error {
 		if err := g.genEncoder(t); err != nil {
 			return err
 		}
+		if g.fullMarshalers {
+			g.fullEncoding = true
+			err := g.genEncoder(t)
+			g.fullEncoding = false
+			if err != nil {
+				return err
+			}
+		}
 
 		if !g.marshalers[t] {
 			continue
@@ -208,6 +242,11 @@ func (g *Generator) Run(out io.Writer) error {
 		if err := g.genStructUnmarshaler(t); err != nil {
 			return err
 		}
+		if g.lazyPartials && t.Kind() == reflect.Struct {
+			if err := g.genLazyPartial(t); err != nil {
+				return err
+			}
+		}
 	}
 	g.printHeader()
 	_, err := out.Write(g.out.Bytes())
@@ -363,9 +402,10 @@ func (g *Generator) functionName(prefix string, t reflect.Type) string {
 		return name
 	}
 
-	// Search if the function already exists.
+	// Search if the function already exists. Names of clashes only add a number to the name,
+	// so that prefixes of other prefixes, such as encode of encodeFull, are not matched.
 	for name1, t1 := range g.functionNames {
-		if t1 == t && strings.HasPrefix(name1, prefix) {
+		if t1 == t && strings.HasPrefix(name1, name) && strings.Trim(name1[len(name):], "0123456789") == "" {
 			return name1
 		}
 	}
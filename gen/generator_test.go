@@ -1,7 +1,12 @@
 package gen
 
 import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestCamelToSnake(t *testing.T) {
@@ -70,6 +75,90 @@ func TestJoinFunctionNameParts(t *testing.T) {
 	}
 }
 
+func TestFunctionName(t *testing.T) {
+	g := NewGenerator("test.go")
+	a, b := reflect.TypeOf(fieldTags{}), reflect.TypeOf(variant{})
+
+	// Names of types of a package clash, so that a gets numbered names.
+	other := g.functionName("encode", b)
+	full := g.functionName("encodeFull", a)
+	if got := g.functionName("encode", a); got == full || got == other {
+		t.Errorf("functionName(encode) = %s; want other than %s and %s", got, full, other)
+	}
+	if got := g.functionName("encodeFull", a); got != full {
+		t.Errorf("functionName(encodeFull) = %s; want %s", got, full)
+	}
+}
+
+type fullUser struct {
+	Name string `json:"name"`
+}
+
+func TestFullMarshalers(t *testing.T) {
+	for _, full := range []bool{false, true} {
+		g := NewGenerator("test.go")
+		g.SetPkg("gen", reflect.TypeOf(fullUser{}).PkgPath())
+		if full {
+			g.FullMarshalers()
+		}
+		g.Add(fullUser{})
+
+		var out bytes.Buffer
+		if err := g.Run(&out); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(out.String(), "MarshalFullJSON"); got != full {
+			t.Errorf("FullMarshalers() %v: MarshalFullJSON generated = %v", full, got)
+		}
+		if got := strings.Contains(strings.ToLower(out.String()), "encodefull"); got != full {
+			t.Errorf("FullMarshalers() %v: full encoders generated = %v", full, got)
+		}
+	}
+}
+
+// PartialityReport is a model whose name starts with Partial, and cipherReport1 a partial whose
+// name was changed to avoid a clash, as the partial generator does.
+type PartialityReport struct {
+	Token string `json:"token"`
+}
+
+type PartialPartialityReport struct {
+	Token        string   `json:"token"`
+	PartialValid struct{} `json:"-" partial:"of=PartialityReport"`
+}
+
+type cipherReport1 struct {
+	Token        string   `json:"token"`
+	PartialValid struct{} `json:"-" partial:"of=cipherReport"`
+}
+
+func TestCipherPath(t *testing.T) {
+	g := NewGenerator("test.go")
+	for i, test := range []struct {
+		Type reflect.Type
+		Want string
+	}{
+		{reflect.TypeOf(PartialPartialityReport{}), "PartialityReport.token"},
+		{reflect.TypeOf(cipherReport1{}), "cipherReport.token"},
+		{reflect.TypeOf(PartialityReport{}), "PartialityReport.token"},
+	} {
+		if got := g.cipherPath(test.Type, test.Type.Field(0)); got != test.Want {
+			t.Errorf("[%d] cipherPath(%v) = %s; want %s", i, test.Type, got, test.Want)
+		}
+	}
+
+	p := NewPartialGenerator("test.go")
+	p.SetPkg("gen", reflect.TypeOf(PartialityReport{}).PkgPath())
+	p.Add(PartialityReport{})
+	var out bytes.Buffer
+	if err := p.Run(&out); err != nil {
+		t.Fatal(err)
+	}
+	if want := `partial:"of=PartialityReport"`; !strings.Contains(out.String(), want) {
+		t.Errorf("partial struct does not record its model with %s", want)
+	}
+}
+
 func TestFixVendorPath(t *testing.T) {
 	for i, test := range []struct {
 		In, Out string
@@ -85,3 +174,217 @@ func TestFixVendorPath(t *testing.T) {
 	}
 
 }
+
+func TestParseFieldTagsTimeFormat(t *testing.T) {
+	for i, test := range []struct {
+		Tag            reflect.StructTag
+		Time, Duration string
+	}{
+		{`json:"at"`, "", ""},
+		{`partial:"time=unixms"`, "unixms", ""},
+		{`partial:"duration=ms"`, "", "ms"},
+		{`json:"at,omitempty" partial:"time=layout:Mon, 02 Jan 2006; duration=string"`, "layout:Mon, 02 Jan 2006", "string"},
+	} {
+		got := parseFieldTags(reflect.StructField{Tag: test.Tag})
+		if got.timeFormat != test.Time || got.durationFormat != test.Duration {
+			t.Errorf("[%d] parseFieldTags(%s) = %q, %q; want %q, %q", i, test.Tag, got.timeFormat, got.durationFormat, test.Time, test.Duration)
+		}
+	}
+}
+
+func TestParseFieldTagsOptions(t *testing.T) {
+	for i, test := range []struct {
+		Tag                reflect.StructTag
+		Replace, Sensitive bool
+	}{
+		{`json:"home"`, false, false},
+		{`partial:"replace"`, true, false},
+		{`partial:"time=unix; replace"`, true, false},
+		{`partial:"replace=false"`, false, false},
+		{`partial:"sensitive;replace"`, true, true},
+	} {
+		got := parseFieldTags(reflect.StructField{Tag: test.Tag})
+		if got.replace != test.Replace || got.sensitive != test.Sensitive {
+			t.Errorf("[%d] parseFieldTags(%s) = replace %v, sensitive %v; want %v, %v", i, test.Tag, got.replace, got.sensitive, test.Replace, test.Sensitive)
+		}
+	}
+}
+
+func TestParseFieldTagsViews(t *testing.T) {
+	for i, test := range []struct {
+		Tag  reflect.StructTag
+		Want []string
+	}{
+		{`json:"email"`, nil},
+		{`partial:"view=admin"`, []string{"admin"}},
+		{`partial:"replace; view=public, internal,admin"`, []string{"public", "internal", "admin"}},
+	} {
+		if got := parseFieldTags(reflect.StructField{Tag: test.Tag}).views; !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("[%d] parseFieldTags(%s).views = %q; want %q", i, test.Tag, got, test.Want)
+		}
+	}
+}
+
+func TestParseFieldTagsWrite(t *testing.T) {
+	for i, test := range []struct {
+		Tag  reflect.StructTag
+		Want []string
+	}{
+		{`json:"role"`, nil},
+		{`partial:"write=admin"`, []string{"admin"}},
+		{`partial:"view=admin; write=admin | owner"`, []string{"admin", "owner"}},
+	} {
+		if got := parseFieldTags(reflect.StructField{Tag: test.Tag}).write; !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("[%d] parseFieldTags(%s).write = %q; want %q", i, test.Tag, got, test.Want)
+		}
+	}
+}
+
+func TestTimeLayout(t *testing.T) {
+	for i, test := range []struct {
+		Format, Layout string
+		Error          bool
+	}{
+		{"unix", "", false},
+		{"rfc3339nano", time.RFC3339Nano, false},
+		{"layout:2006-01-02", "2006-01-02", false},
+
+		{"layout:", "", true},
+		{`layout:"2006"`, "", true},
+		{"iso", "", true},
+	} {
+		got, err := timeLayout(test.Format)
+		if got != test.Layout || (err != nil) != test.Error {
+			t.Errorf("[%d] timeLayout(%s) = %q, %v; want %q, error %v", i, test.Format, got, err, test.Layout, test.Error)
+		}
+	}
+}
+
+func TestDefaultLiteral(t *testing.T) {
+	var f32 *float32
+	for i, test := range []struct {
+		Type    reflect.Type
+		Default string
+		Literal string
+		Error   bool
+	}{
+		{reflect.TypeOf(""), `a "b"`, `"a \"b\""`, false},
+		{reflect.TypeOf(false), "true", "true", false},
+		{reflect.TypeOf(int8(0)), "-128", "-128", false},
+		{reflect.TypeOf(f32), "0.1", "0.1", false},
+		{reflect.TypeOf(time.Duration(0)), "1m30s", "90000000000", false},
+
+		{reflect.TypeOf(int8(0)), "128", "", true},
+		{reflect.TypeOf(uint(0)), "-1", "", true},
+		{reflect.TypeOf(0.0), "Inf", "", true},
+		{reflect.TypeOf(time.Duration(0)), "90", "", true},
+		{reflect.TypeOf([]int{}), "1", "", true},
+	} {
+		got, err := defaultLiteral(test.Type, test.Default)
+		if got != test.Literal || (err != nil) != test.Error {
+			t.Errorf("[%d] defaultLiteral(%v, %q) = %s, %v; want %s, error %v", i, test.Type, test.Default, got, err, test.Literal, test.Error)
+		}
+	}
+}
+
+func TestTypeString(t *testing.T) {
+	g := NewPartialGenerator("test.go")
+	g.SetPkg("gen", "github.com/reddyvinod/partialencode/gen")
+	for i, test := range []struct {
+		Type reflect.Type
+		Want string
+	}{
+		{reflect.TypeOf(0), "int"},
+		{reflect.TypeOf(fieldTags{}), "fieldTags"},
+		{reflect.TypeOf(map[string][]*time.Time{}), "map[string][]*time.Time"},
+		{reflect.TypeOf([2]interface{}{}), "[2]interface {}"},
+		{reflect.TypeOf(struct {
+			A int `json:"a"`
+			fieldTags
+		}{}), "struct {A int \"json:\\\"a\\\"\"; fieldTags}"},
+	} {
+		if got := g.typeString(test.Type); got != test.Want {
+			t.Errorf("[%d] typeString(%v) = %s; want %s", i, test.Type, got, test.Want)
+		}
+	}
+}
+
+type quotedTags struct {
+	A int "json:\"a\" tag:\"with ` in it\""
+}
+
+func TestPartialStructTags(t *testing.T) {
+	g := NewPartialGenerator("test.go")
+	g.SetPkg("gen", reflect.TypeOf(quotedTags{}).PkgPath())
+	g.Add(quotedTags{})
+
+	var out bytes.Buffer
+	if err := g.Run(&out); err != nil {
+		t.Fatal(err)
+	}
+	if want := `"json:\"a\" tag:\"with ` + "`" + ` in it\""`; !strings.Contains(out.String(), want) {
+		t.Errorf("partial struct does not quote the tag as %s:\n%s", want, out.String())
+	}
+}
+
+func TestZeroCheck(t *testing.T) {
+	g := NewPartialGenerator("test.go")
+	g.SetPkg("gen", "github.com/reddyvinod/partialencode/gen")
+	for i, test := range []struct {
+		Type reflect.Type
+		Want string
+	}{
+		{reflect.TypeOf(false), "!v"},
+		{reflect.TypeOf(""), `v == ""`},
+		{reflect.TypeOf(uint8(0)), "v == 0"},
+		{reflect.TypeOf(fieldTags{}), "(v).Equal((fieldTags{}))"},
+		{reflect.TypeOf(time.Time{}), "(v).Equal((time.Time{}))"},
+		{reflect.TypeOf([2]int{}), "func() bool {\n  for v1 := range v {\n    if (v)[v1] != (([2]int{}))[v1] {\n      return false\n    }\n  }\n  return true\n}()"},
+	} {
+		if got := g.zeroCheck(test.Type, "v"); got != test.Want {
+			t.Errorf("[%d] zeroCheck(%v) = %s; want %s", i, test.Type, got, test.Want)
+		}
+	}
+}
+
+func TestNeedsDeepCopy(t *testing.T) {
+	g := NewPartialGenerator("test.go")
+	g.SetPkg("gen", "github.com/reddyvinod/partialencode/gen")
+	for i, test := range []struct {
+		Type reflect.Type
+		Want bool
+	}{
+		{reflect.TypeOf(0), false},
+		{reflect.TypeOf(time.Time{}), false},
+		{reflect.TypeOf([2]string{}), false},
+		{reflect.TypeOf([2][]string{}), true},
+		{reflect.TypeOf(fieldTags{}), true},
+		{reflect.TypeOf(struct{ A int }{}), false},
+		{reflect.TypeOf(struct{ A *int }{}), true},
+		{reflect.TypeOf((*error)(nil)).Elem(), false},
+		{reflect.TypeOf(big.Int{}), true},
+		{reflect.TypeOf(struct{ A big.Float }{}), true},
+	} {
+		if got := g.needsDeepCopy(test.Type); got != test.Want {
+			t.Errorf("[%d] needsDeepCopy(%v) = %v; want %v", i, test.Type, got, test.Want)
+		}
+	}
+	if !hasEqualMethod(reflect.TypeOf(time.Time{})) || hasEqualMethod(reflect.TypeOf(fieldTags{})) {
+		t.Error("hasEqualMethod() is wrong")
+	}
+	if !hasCmpMethod(reflect.TypeOf(big.Int{})) || hasCmpMethod(reflect.TypeOf(time.Time{})) {
+		t.Error("hasCmpMethod() is wrong")
+	}
+	for _, test := range []struct {
+		Type reflect.Type
+		Want string
+	}{
+		{reflect.TypeOf(big.Int{}), "Set"},
+		{reflect.TypeOf(big.Float{}), "Copy"},
+		{reflect.TypeOf(time.Time{}), ""},
+	} {
+		if got := copyMethod(test.Type); got != test.Want {
+			t.Errorf("copyMethod(%v) = %q; want %q", test.Type, got, test.Want)
+		}
+	}
+}
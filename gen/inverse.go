@@ -0,0 +1,95 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Inverse requests to generate InversePartial<T>, returning the partials that undo partials.
+func (g *PartialGenerator) Inverse() {
+	g.inverse = true
+}
+
+// zeroCheck returns the expression checking whether v of type t is the zero value, which null
+// resets fields of non-nullable types to.
+func (g *PartialGenerator) zeroCheck(t reflect.Type, v string) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "!" + v
+	case reflect.String:
+		return v + ` == ""`
+	case reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v + " == 0"
+	}
+	return g.equalExpr(t, v, "("+g.typeString(t)+"{})", false)
+}
+
+// genInverse generates the function returning the partial that undoes a partial of t applied
+// to a t.
+func (g *PartialGenerator) genInverse(t reflect.Type) {
+	sname := g.getStructName(t)
+
+	fmt.Fprintf(g.out, "// Inverse%s returns the partial that restores orig after p is applied to it. It has\n", sname)
+	fmt.Fprintln(g.out, "// the fields touched by p, set to null where orig is nil or the zero value null resets to.")
+	fmt.Fprintln(g.out, "func Inverse"+sname+"(orig "+t.Name()+", p "+sname+") "+sname+" {")
+	fmt.Fprintln(g.out, "  var inv "+sname)
+	for i := 0; i < t.NumField(); i++ {
+		g.genInverseField(t.Field(i))
+	}
+	fmt.Fprintln(g.out, "  return inv")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+}
+
+func (g *PartialGenerator) genInverseField(f reflect.StructField) {
+	valid := func(v string) string { return v + "." + PartialValidKey + "." + f.Name }
+	set := func(v string) string { return v + "." + PartialSetKey + "." + f.Name }
+	orig := "orig." + f.Name
+
+	fmt.Fprintln(g.out, "  switch {")
+	fmt.Fprintln(g.out, "  case !"+valid("p")+" && !"+set("p")+":")
+
+	// Nested partials patch the structs they are applied to, see genApplyFields, and are
+	// undone by the inverse patches.
+	if !f.Anonymous && g.trackedNested(f) && !parseFieldTags(f).replace {
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+			vVar := g.uniqueVarName()
+			fmt.Fprintln(g.out, "  case "+valid("p")+" && p."+f.Name+" != nil && "+orig+" != nil:")
+			fmt.Fprintln(g.out, "    "+vVar+" := Inverse"+g.getStructName(ft)+"(*"+orig+", *p."+f.Name+")")
+			fmt.Fprintln(g.out, "    inv."+f.Name+" = &"+vVar)
+		} else {
+			fmt.Fprintln(g.out, "  case "+valid("p")+":")
+			fmt.Fprintln(g.out, "    inv."+f.Name+" = Inverse"+g.getStructName(ft)+"("+orig+", p."+f.Name+")")
+		}
+		fmt.Fprintln(g.out, "    "+valid("inv")+" = true")
+	}
+
+	// Other fields are restored as a whole.
+	switch {
+	case f.Anonymous && f.Type.Kind() == reflect.Ptr:
+		fmt.Fprintln(g.out, "  case "+orig+" == nil:")
+		fmt.Fprintln(g.out, "    "+set("inv")+" = true")
+		fmt.Fprintln(g.out, "  default:")
+		fmt.Fprintln(g.out, "    inv."+f.Name+" = *"+orig)
+		fmt.Fprintln(g.out, "    "+valid("inv")+" = true")
+	case f.Anonymous:
+		fmt.Fprintln(g.out, "  default:")
+		fmt.Fprintln(g.out, "    inv."+f.Name+" = "+orig)
+		fmt.Fprintln(g.out, "    "+valid("inv")+" = true")
+	default:
+		if isNullable(f.Type) {
+			fmt.Fprintln(g.out, "  case "+orig+" == nil:")
+		} else {
+			fmt.Fprintln(g.out, "  case "+g.zeroCheck(f.Type, orig)+":")
+		}
+		fmt.Fprintln(g.out, "    "+set("inv")+" = true")
+		fmt.Fprintln(g.out, "  default:")
+		g.genToPartial(f.Type, orig, "inv."+f.Name, 2)
+		fmt.Fprintln(g.out, "    "+valid("inv")+" = true")
+	}
+	fmt.Fprintln(g.out, "  }")
+}
@@ -0,0 +1,274 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/reddyvinod/partialencode"
+)
+
+// lazyFields returns the fields of a partial struct that are stored by its lazy counterpart.
+func lazyFields(t reflect.Type) ([]reflect.StructField, error) {
+	fs, err := getStructFields(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []reflect.StructField
+	for _, f := range fs {
+		switch f.Name {
+		case PartialValidKey, PartialSetKey:
+			continue
+		}
+		if parseFieldTags(f).omit {
+			continue
+		}
+		ret = append(ret, f)
+	}
+	return ret, nil
+}
+
+// genLazyPartial generates a LazyPartial type for the partial struct t. It keeps raw values of
+// the fields found during decoding and decodes them on the first access through getters.
+func (g *Generator) genLazyPartial(t reflect.Type) error {
+	fs, err := lazyFields(t)
+	if err != nil {
+		return fmt.Errorf("cannot generate lazy partial for %v: %v", t, err)
+	}
+
+	typ := g.getType(t)
+	lazy := "Lazy" + t.Name()
+	flags := g.getType(t.Field(fieldIndex(t, PartialValidKey)).Type)
+
+	fmt.Fprintf(g.out, "// %s is a %s that keeps the raw values of the fields and decodes them\n", lazy, typ)
+	fmt.Fprintln(g.out, "// on first access through the getters. The flags are available right after decoding.")
+	fmt.Fprintln(g.out, "// Unless the lexer is streaming, the raw values point to the decoded data, so it must")
+	fmt.Fprintln(g.out, "// not be modified while the value is used. The raw values are checked against the input")
+	fmt.Fprintln(g.out, "// limits of the lexer while decoding, and decoded with its limits and options.")
+	fmt.Fprintln(g.out, "type "+lazy+" struct {")
+	fmt.Fprintln(g.out, "  "+PartialValidKey+" "+flags)
+	fmt.Fprintln(g.out, "  "+PartialSetKey+" "+flags)
+	fmt.Fprintln(g.out)
+	fmt.Fprintln(g.out, "  raw     ["+strconv.Itoa(len(fs))+"][]byte")
+	fmt.Fprintln(g.out, "  lexer   *jlexer.Lexer")
+	fmt.Fprintln(g.out, "  decoded "+flags)
+	fmt.Fprintln(g.out, "  value   "+typ)
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	for i, f := range fs {
+		if err := g.genLazyGetter(t, lazy, i, f); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(g.out, "// Partial decodes all the fields and returns them as %s.\n", typ)
+	fmt.Fprintln(g.out, "func (v *"+lazy+") Partial() ("+typ+", error) {")
+	for _, f := range fs {
+		fmt.Fprintln(g.out, "  if _, err := v.Get"+f.Name+"(); err != nil {")
+		fmt.Fprintln(g.out, "    return v.value, err")
+		fmt.Fprintln(g.out, "  }")
+	}
+	fmt.Fprintln(g.out, "  v.value."+PartialValidKey+" = v."+PartialValidKey)
+	fmt.Fprintln(g.out, "  v.value."+PartialSetKey+" = v."+PartialSetKey)
+	fmt.Fprintln(g.out, "  return v.value, nil")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	if err := g.genLazyDecoder(t, lazy, fs); err != nil {
+		return err
+	}
+	return g.genLazyEncoder(t, lazy, fs)
+}
+
+// fieldIndex returns the index of the named field of the struct t.
+func fieldIndex(t reflect.Type, name string) int {
+	f, _ := t.FieldByName(name)
+	return f.Index[0]
+}
+
+func (g *Generator) genLazyGetter(t reflect.Type, lazy string, i int, f reflect.StructField) error {
+	typ := g.getType(f.Type)
+	raw := "v.raw[" + strconv.Itoa(i) + "]"
+
+	fmt.Fprintf(g.out, "// Get%s returns the value of the %s field, decoding it on the first call.\n", f.Name, f.Name)
+	fmt.Fprintln(g.out, "func (v *"+lazy+") Get"+f.Name+"() ("+typ+", error) {")
+	fmt.Fprintln(g.out, "  if !v.decoded."+f.Name+" && "+raw+" != nil {")
+	fmt.Fprintln(g.out, "    in := v.lexer.SubLexer("+raw+")")
+	if err := g.genFieldValueDecoder(t, f, "v.value."+f.Name, parseFieldTags(f), 2); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, "    if err := in.Error(); err != nil {")
+	fmt.Fprintln(g.out, "      return v.value."+f.Name+", err")
+	fmt.Fprintln(g.out, "    }")
+	fmt.Fprintln(g.out, "    v.decoded."+f.Name+" = true")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "  return v.value."+f.Name+", nil")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	fmt.Fprintf(g.out, "// Set%s sets the value of the %s field.\n", f.Name, f.Name)
+	fmt.Fprintln(g.out, "func (v *"+lazy+") Set"+f.Name+"(value "+typ+") {")
+	fmt.Fprintln(g.out, "  v.value."+f.Name+" = value")
+	fmt.Fprintln(g.out, "  v.decoded."+f.Name+" = true")
+	fmt.Fprintln(g.out, "  v."+PartialValidKey+"."+f.Name+" = true")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+	return nil
+}
+
+func (g *Generator) genLazyDecoder(t reflect.Type, lazy string, fs []reflect.StructField) error {
+	fmt.Fprintln(g.out, "// UnMarshalPartialJSON supports partialencode.Unmarshaler interface")
+	fmt.Fprintln(g.out, "func (v *"+lazy+") UnMarshalPartialJSON(in *jlexer.Lexer) {")
+	fmt.Fprintln(g.out, "  *v = "+lazy+"{lexer: in.SubLexer(nil)}")
+	fmt.Fprintln(g.out, "  isTopLevel := in.IsStart()")
+	fmt.Fprintln(g.out, "  if in.IsNull() {")
+	fmt.Fprintln(g.out, "    if isTopLevel {")
+	fmt.Fprintln(g.out, "      in.Consumed()")
+	fmt.Fprintln(g.out, "    }")
+	fmt.Fprintln(g.out, "    in.Skip()")
+	fmt.Fprintln(g.out, "    return")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "  in.Delim('{')")
+	fmt.Fprintln(g.out, "  for !in.IsDelim('}') {")
+	fmt.Fprintln(g.out, "    key := in.UnsafeString()")
+	fmt.Fprintln(g.out, "    in.WantColon()")
+	fmt.Fprintln(g.out, "    switch key {")
+	for i, f := range fs {
+		fmt.Fprintf(g.out, "    case %q:\n", g.fieldNamer.GetJSONFieldName(t, f))
+		g.genAuthorize(parseFieldTags(f), 3)
+		fmt.Fprintln(g.out, "      if in.IsNull() {")
+		fmt.Fprintln(g.out, "        in.Skip()")
+		fmt.Fprintln(g.out, "        v."+PartialSetKey+"."+f.Name+" = true")
+		if mayHaveTaggedFields(f.Type) {
+			// Nested fields are authorized with their paths while decoding.
+			fmt.Fprintln(g.out, "      } else if in.Policy != nil {")
+			if err := g.genFieldValueDecoder(t, f, "v.value."+f.Name, parseFieldTags(f), 4); err != nil {
+				return err
+			}
+			fmt.Fprintln(g.out, "        v.decoded."+f.Name+" = true")
+			fmt.Fprintln(g.out, "        v."+PartialValidKey+"."+f.Name+" = true")
+		}
+		fmt.Fprintln(g.out, "      } else {")
+		fmt.Fprintln(g.out, "        v.raw["+strconv.Itoa(i)+"] = in.KeepRaw()")
+		fmt.Fprintln(g.out, "        v."+PartialValidKey+"."+f.Name+" = true")
+		fmt.Fprintln(g.out, "      }")
+	}
+	if err := g.genDiscriminatorCase(t, fs); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, "    default:")
+	if g.disallowUnknownFields {
+		fmt.Fprintln(g.out, `      in.AddError(&jlexer.LexerError{
+          Offset: in.GetPos(),
+          Reason: "unknown field",
+          Data: string([]byte(key)),
+      })`)
+	} else {
+		fmt.Fprintln(g.out, "      in.SkipRecursive()")
+	}
+	fmt.Fprintln(g.out, "    }")
+	fmt.Fprintln(g.out, "    in.WantComma()")
+	fmt.Fprintln(g.out, "  }")
+	fmt.Fprintln(g.out, "  in.Delim('}')")
+	fmt.Fprintln(g.out, "  if isTopLevel {")
+	fmt.Fprintln(g.out, "    in.Consumed()")
+	fmt.Fprintln(g.out, "  }")
+
+	// Absent fields have no raw values, so their getters return the defaults.
+	defaulterIface := reflect.TypeOf((*partialencode.Defaulter)(nil)).Elem()
+	if reflect.PtrTo(t).Implements(defaulterIface) {
+		fmt.Fprintln(g.out, "  v.value."+PartialValidKey+" = v."+PartialValidKey)
+		fmt.Fprintln(g.out, "  v.value."+PartialSetKey+" = v."+PartialSetKey)
+		fmt.Fprintln(g.out, "  v.value.ApplyDefaults()")
+	}
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	if !g.noStdMarshalers {
+		fmt.Fprintln(g.out, "// UnmarshalJSON supports json.Unmarshaler interface")
+		fmt.Fprintln(g.out, "func (v *"+lazy+") UnmarshalJSON(data []byte) error {")
+		fmt.Fprintln(g.out, "  r := jlexer.Lexer{Data: append([]byte(nil), data...)}")
+		fmt.Fprintln(g.out, "  v.UnMarshalPartialJSON(&r)")
+		fmt.Fprintln(g.out, "  return r.Error()")
+		fmt.Fprintln(g.out, "}")
+		fmt.Fprintln(g.out)
+	}
+	return nil
+}
+
+// mayHaveTaggedFields returns whether values of the type t may contain structs, whose fields
+// may be tagged with views or write roles.
+func mayHaveTaggedFields(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return mayHaveTaggedFields(t.Elem())
+	case reflect.Struct, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+func (g *Generator) genLazyEncoder(t reflect.Type, lazy string, fs []reflect.StructField) error {
+	fmt.Fprintln(g.out, "// MarshalPartialJSON supports partialencode.Marshaler interface. Fields that were not")
+	fmt.Fprintln(g.out, "// accessed are written as they were decoded.")
+	fmt.Fprintln(g.out, "func (v *"+lazy+") MarshalPartialJSON(out *jwriter.Writer) {")
+	g.genObjectStart(t, "  ")
+	for i, f := range fs {
+		tags := parseFieldTags(f)
+		raw := "v.raw[" + strconv.Itoa(i) + "]"
+
+		g.genFilterStart(tags)
+		fmt.Fprintln(g.out, "  if v."+PartialValidKey+"."+f.Name+" {")
+		if err := g.genStructFieldEncoder(t, f); err != nil {
+			return err
+		}
+		// Nested fields may be outside of the view or redacted, and the hash of a redacted value
+		// must not depend on the formatting of the input, so such values are decoded.
+		decode := ""
+		switch {
+		case mayHaveTaggedFields(f.Type):
+			decode = "(out.View != \"\" || out.RedactSensitive != jwriter.RedactNone)"
+		case tags.sensitive:
+			decode = "out.RedactSensitive != jwriter.RedactNone"
+		}
+		if decode != "" {
+			fmt.Fprintln(g.out, "    if !v.decoded."+f.Name+" && "+raw+" != nil && "+decode+" {")
+			fmt.Fprintln(g.out, "      if _, err := v.Get"+f.Name+"(); err != nil {")
+			fmt.Fprintln(g.out, "        out.Error = err")
+			fmt.Fprintln(g.out, "      }")
+			fmt.Fprintln(g.out, "    }")
+		}
+		fmt.Fprintln(g.out, "    if v.decoded."+f.Name+" || "+raw+" == nil {")
+		if err := g.genFieldValueEncoder(t, f, "v.value."+f.Name, tags, 3); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, "    } else {")
+		fmt.Fprintln(g.out, "      out.Raw("+raw+", nil)")
+		fmt.Fprintln(g.out, "    }")
+		if tags.shownull || g.unsetNull {
+			fmt.Fprintln(g.out, "  } else if v."+PartialSetKey+"."+f.Name+" {")
+			if err := g.genStructFieldEncoder(t, f); err != nil {
+				return err
+			}
+			fmt.Fprintln(g.out, `    out.RawString("null")`)
+		}
+		fmt.Fprintln(g.out, "  }")
+		g.genFilterEnd(tags)
+	}
+	fmt.Fprintln(g.out, "  out.RawByte('}')")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	if !g.noStdMarshalers {
+		fmt.Fprintln(g.out, "// MarshalJSON supports json.Marshaler interface")
+		fmt.Fprintln(g.out, "func (v *"+lazy+") MarshalJSON() ([]byte, error) {")
+		fmt.Fprintln(g.out, "  w := jwriter.Writer{}")
+		fmt.Fprintln(g.out, "  v.MarshalPartialJSON(&w)")
+		fmt.Fprintln(g.out, "  return w.Buffer.BuildBytes(), w.Error")
+		fmt.Fprintln(g.out, "}")
+		fmt.Fprintln(g.out)
+	}
+	return nil
+}
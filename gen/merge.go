@@ -0,0 +1,161 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/reddyvinod/partialencode/jlexer"
+)
+
+// Merge requests to generate three-way merges of partial structs, MergePartial<T>.
+func (g *PartialGenerator) Merge() {
+	g.merge = true
+}
+
+// equalExpr returns the expression comparing the values a and b of type t, or of its partial
+// counterpart in partial mode. Values other than basic ones are compared as genEqual compares
+// them, so that pointers are equal if they point to equal values.
+func (g *PartialGenerator) equalExpr(t reflect.Type, a, b string, partial bool) string {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+	default:
+		if hasEqualMethod(t) {
+			return "(" + a + ").Equal(" + b + ")"
+		}
+		return a + " == " + b
+	}
+	if t.Kind() == reflect.Struct && (g.hasPartial(t) || hasEqualMethod(t)) {
+		return "(" + a + ").Equal(" + b + ")"
+	}
+
+	out := g.out
+	g.out = &bytes.Buffer{}
+	g.genEqual(t, a, b, partial, 1)
+	body := g.out.String()
+	g.out = out
+	return "func() bool {\n" + body + "  return true\n}()"
+}
+
+// genMerge generates the three-way merge of partials of the struct t. A field changed by only
+// one partial, or by both to the same value, is taken from the partial that changed it. A
+// change that keeps the value of the base gives way to the change of the other partial, and
+// nested partials that both change are merged recursively. Other fields are conflicts.
+func (g *PartialGenerator) genMerge(t reflect.Type) {
+	sname := g.getStructName(t)
+	fname := g.helperName("merge", t)
+	pe := g.pkgAlias(pkgPartialEncode)
+
+	fmt.Fprintf(g.out, "// Merge%s merges the partials a and b of base. Fields changed by only one of them, or by\n", sname)
+	fmt.Fprintln(g.out, "// both to the same value, are merged; the others are returned as conflicts, which are")
+	fmt.Fprintln(g.out, "// settled with the values of a.")
+	fmt.Fprintln(g.out, "func Merge"+sname+"(base "+t.Name()+", a, b "+sname+") ("+sname+", []"+pe+".Conflict) {")
+	fmt.Fprintln(g.out, "  return Merge"+sname+"With(base, a, b, "+pe+".ResolveOurs)")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	fmt.Fprintf(g.out, "// Merge%sWith merges the partials a and b of base as Merge%s does, settling conflicts\n", sname, sname)
+	fmt.Fprintln(g.out, "// with resolve.")
+	fmt.Fprintln(g.out, "func Merge"+sname+"With(base "+t.Name()+", a, b "+sname+", resolve "+pe+".Resolver) ("+sname+", []"+pe+".Conflict) {")
+	fmt.Fprintln(g.out, "  var cs []"+pe+".Conflict")
+	fmt.Fprintln(g.out, "  p := "+fname+"(\"$\", base, a, b, resolve, &cs)")
+	fmt.Fprintln(g.out, "  return p, cs")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	fmt.Fprintln(g.out, "func "+fname+"(path string, base "+t.Name()+", a, b "+sname+", resolve "+pe+".Resolver, cs *[]"+pe+".Conflict) "+sname+" {")
+	fmt.Fprintln(g.out, "  var p "+sname)
+	for i := 0; i < t.NumField(); i++ {
+		g.genMergeField(t, t.Field(i))
+	}
+	fmt.Fprintln(g.out, "  return p")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+}
+
+func (g *PartialGenerator) genMergeField(t reflect.Type, f reflect.StructField) {
+	valid := func(v string) string { return v + "." + PartialValidKey + "." + f.Name }
+	changed := func(v string) string { return "(" + valid(v) + " || " + v + "." + PartialSetKey + "." + f.Name + ")" }
+	take := func(v, ws string) {
+		fmt.Fprintln(g.out, ws+"p."+f.Name+" = "+v+"."+f.Name)
+		fmt.Fprintln(g.out, ws+"p."+PartialValidKey+"."+f.Name+" = "+v+"."+PartialValidKey+"."+f.Name)
+		fmt.Fprintln(g.out, ws+"p."+PartialSetKey+"."+f.Name+" = "+v+"."+PartialSetKey+"."+f.Name)
+	}
+
+	// The partial type of embedded fields is the type of the field, see genFieldPartialStruct.
+	ptype := f.Type
+	if f.Anonymous && ptype.Kind() == reflect.Ptr {
+		ptype = ptype.Elem()
+	}
+
+	// Whether the value of a partial can be compared with the base.
+	same := valid("a") + " == " + valid("b") + " && (!" + valid("a") + " || " + g.equalExpr(ptype, "a."+f.Name, "b."+f.Name, !f.Anonymous) + ")"
+	keepsBase := func(v string) string {
+		if f.Anonymous || !g.keepsType(f.Type) {
+			return ""
+		}
+		expr := valid(v) + " && " + g.equalExpr(f.Type, v+"."+f.Name, "base."+f.Name, false)
+		if isNullable(f.Type) {
+			expr += " || !" + valid(v) + " && base." + f.Name + " == nil"
+		}
+		return expr
+	}
+
+	name := g.fieldNamer.GetJSONFieldName(t, f)
+	if parseFieldTags(f).omit {
+		name = f.Name
+	}
+
+	fmt.Fprintln(g.out, "  switch {")
+	fmt.Fprintln(g.out, "  case !"+changed("a")+":")
+	take("b", "    ")
+	if keepsBase("b") == "" {
+		fmt.Fprintln(g.out, "  case !"+changed("b")+" || "+same+":")
+		take("a", "    ")
+	} else {
+		fmt.Fprintln(g.out, "  case !"+changed("b")+" || "+same+" || "+keepsBase("b")+":")
+		take("a", "    ")
+		fmt.Fprintln(g.out, "  case "+keepsBase("a")+":")
+		take("b", "    ")
+	}
+
+	if !f.Anonymous && g.trackedNested(f) {
+		ft := f.Type
+		cond := valid("a") + " && " + valid("b")
+		if ft.Kind() == reflect.Ptr {
+			cond += " && a." + f.Name + " != nil && b." + f.Name + " != nil"
+		}
+		fmt.Fprintln(g.out, "  case "+cond+":")
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+			bVar := g.uniqueVarName()
+			mVar := g.uniqueVarName()
+			fmt.Fprintln(g.out, "    var "+bVar+" "+ft.Name())
+			fmt.Fprintln(g.out, "    if base."+f.Name+" != nil {")
+			fmt.Fprintln(g.out, "      "+bVar+" = *base."+f.Name)
+			fmt.Fprintln(g.out, "    }")
+			fmt.Fprintf(g.out, "    %s := %s(path+%q, %s, *a.%s, *b.%s, resolve, cs)\n", mVar, g.helperName("merge", ft), jlexer.PathKey(name), bVar, f.Name, f.Name)
+			fmt.Fprintln(g.out, "    p."+f.Name+" = &"+mVar)
+		} else {
+			fmt.Fprintf(g.out, "    p.%s = %s(path+%q, base.%s, a.%s, b.%s, resolve, cs)\n", f.Name, g.helperName("merge", ft), jlexer.PathKey(name), f.Name, f.Name, f.Name)
+		}
+		fmt.Fprintln(g.out, "    p."+PartialValidKey+"."+f.Name+" = true")
+	}
+
+	pe := g.pkgAlias(pkgPartialEncode)
+	fmt.Fprintln(g.out, "  default:")
+	fmt.Fprintf(g.out, "    c := %s.Conflict{Path: path + %q}\n", pe, jlexer.PathKey(name))
+	fmt.Fprintln(g.out, "    if "+valid("a")+" {")
+	fmt.Fprintln(g.out, "      c.Ours = a."+f.Name)
+	fmt.Fprintln(g.out, "    }")
+	fmt.Fprintln(g.out, "    if "+valid("b")+" {")
+	fmt.Fprintln(g.out, "      c.Theirs = b."+f.Name)
+	fmt.Fprintln(g.out, "    }")
+	fmt.Fprintln(g.out, "    *cs = append(*cs, c)")
+	fmt.Fprintln(g.out, "    if resolve(c) == "+pe+".Theirs {")
+	take("b", "      ")
+	fmt.Fprintln(g.out, "    } else {")
+	take("a", "      ")
+	fmt.Fprintln(g.out, "    }")
+	fmt.Fprintln(g.out, "  }")
+}
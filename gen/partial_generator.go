@@ -27,6 +27,12 @@ type PartialGenerator struct {
 	noStdMarshalers       bool
 	omitEmpty             bool
 	disallowUnknownFields bool
+	tracked               bool
+	merge                 bool
+	compose               bool
+	inverse               bool
+	changes               bool
+	deepCopy              bool
 	fieldNamer            FieldNamer
 
 	// package path to local alias map for tracking imports
@@ -35,6 +41,15 @@ type PartialGenerator struct {
 	// types that partials were requested for by user
 	partials map[reflect.Type]bool
 
+	// enum types that methods were requested for
+	enums []enum
+
+	// types registered as variants of interfaces
+	variants []variant
+
+	// types to names of their methods setting default values
+	defaultsHooks map[reflect.Type]string
+
 	// types that encoders were already generated for
 	typesSeen map[reflect.Type]bool
 
@@ -61,6 +76,16 @@ func (g *PartialGenerator) SetBuildTags(tags string) {
 	g.buildTags = tags
 }
 
+// UseSnakeCase sets snake_case field naming strategy.
+func (g *PartialGenerator) UseSnakeCase() {
+	g.fieldNamer = SnakeCaseFieldNamer{}
+}
+
+// UseLowerCamelCase sets lowerCamelCase field naming strategy.
+func (g *PartialGenerator) UseLowerCamelCase() {
+	g.fieldNamer = LowerCamelCaseFieldNamer{}
+}
+
 // addTypes requests to generate encoding/decoding funcs for the given type.
 func (g *PartialGenerator) addType(t reflect.Type) {
 	if g.typesSeen[t] {
@@ -87,6 +112,7 @@ func (g *PartialGenerator) Add(obj interface{}) {
 func NewPartialGenerator(filename string) *PartialGenerator {
 	ret := &PartialGenerator{
 		imports:         make(map[string]string),
+		fieldNamer:      DefaultFieldNamer{},
 		typesSeen:       make(map[reflect.Type]bool),
 		structNames:     make(map[string]reflect.Type),
 		structBoolNames: make(map[string]reflect.Type),
@@ -117,7 +143,46 @@ func (g *PartialGenerator) Run(out io.Writer) error {
 		if err := g.genPartialBoolStruct(t); err != nil {
 			return err
 		}
+
+		if err := g.genDefaults(t); err != nil {
+			return err
+		}
+
+		if g.needsConversion() {
+			g.genToPartialFunc(t)
+		}
+		if g.compose || g.changes {
+			g.genFromPartialFunc(t)
+			g.genApply(t)
+		}
+		if g.compose {
+			g.genCompose(t)
+		}
+		if g.inverse {
+			g.genInverse(t)
+		}
+		if g.changes {
+			g.genChanges(t)
+		}
+		if g.deepCopy {
+			g.genDeepCopy(t)
+		}
+		if g.needsEqual() {
+			g.genEqualMethods(t)
+		}
+		if g.tracked {
+			g.genTracked(t)
+		}
+		if g.merge {
+			g.genMerge(t)
+		}
+	}
+	for _, e := range g.enums {
+		if err := g.genEnum(e); err != nil {
+			return err
+		}
 	}
+	g.genPartialUnions()
 	g.printStructsHeader()
 	_, err := out.Write(g.out.Bytes())
 	return err
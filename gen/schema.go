@@ -0,0 +1,593 @@
+package gen
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reddyvinod/partialencode"
+)
+
+// JSON Schema.
+//
+// SchemaGenerator describes the JSON the encoders and decoders of Generator write and read as
+// JSON Schema (draft 2020-12): a document with a definition of every struct T in $defs and, for
+// structs of the package, of its partial counterpart Partial<T>. Fields are named and mapped to
+// types as by the encoders; the properties of partials are optional and nullable, except for
+// required fields, which the decoders of partials require too.
+//
+// Rules of the validate tag map to schema keywords:
+//
+//	required              the property is required in the schema of T
+//	min, max, len         minLength/maxLength, minItems/maxItems, minProperties/maxProperties
+//	                      or minimum/maximum, depending on the type
+//	gt, gte, lt, lte      exclusiveMinimum, minimum, exclusiveMaximum, maximum of numbers
+//	oneof                 enum of the values separated by spaces
+//	email, url, uri, uuid, ipv4, ipv6, hostname
+//	                      format
+//
+// Other rules, and the rules following dive, are ignored.
+
+// SchemaDraft is the $schema of the generated documents.
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// schema is a JSON Schema object. Keys of maps are encoded in sorted order, so the generated
+// documents are stable.
+type schema map[string]interface{}
+
+// SchemaGenerator generates the JSON Schema of structs and their partial counterparts.
+type SchemaGenerator struct {
+	pkgName string
+	pkgPath string
+
+	fieldNamer            FieldNamer
+	disallowUnknownFields bool
+
+	enums    []enum
+	variants []variant
+
+	// types that definitions were requested for, and the ones already generated
+	typesUnseen []reflect.Type
+	typesSeen   map[reflect.Type]bool
+
+	// definitions by name, and the types they were generated for
+	defs     schema
+	defTypes map[string]reflect.Type
+}
+
+// NewSchemaGenerator initializes and returns a SchemaGenerator.
+func NewSchemaGenerator() *SchemaGenerator {
+	return &SchemaGenerator{
+		fieldNamer: DefaultFieldNamer{},
+		typesSeen:  make(map[reflect.Type]bool),
+		defs:       schema{},
+		defTypes:   make(map[string]reflect.Type),
+	}
+}
+
+// SetPkg sets the name and path of the package whose structs have partial counterparts.
+func (g *SchemaGenerator) SetPkg(name, path string) {
+	g.pkgName = name
+	g.pkgPath = path
+}
+
+// UseSnakeCase sets snake_case field naming strategy.
+func (g *SchemaGenerator) UseSnakeCase() {
+	g.fieldNamer = SnakeCaseFieldNamer{}
+}
+
+// UseLowerCamelCase sets lowerCamelCase field naming strategy.
+func (g *SchemaGenerator) UseLowerCamelCase() {
+	g.fieldNamer = LowerCamelCaseFieldNamer{}
+}
+
+// DisallowUnknownFields disallows properties of objects that are not fields of the structs.
+func (g *SchemaGenerator) DisallowUnknownFields() {
+	g.disallowUnknownFields = true
+}
+
+// Add requests to generate the definitions of the struct type of the given object.
+func (g *SchemaGenerator) Add(obj interface{}) {
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	g.addType(t)
+}
+
+func (g *SchemaGenerator) addType(t reflect.Type) {
+	if !g.typesSeen[t] {
+		g.typesSeen[t] = true
+		g.typesUnseen = append(g.typesUnseen, t)
+	}
+}
+
+// AddEnum registers the enum type of the given object with the names of its constants, see
+// PartialGenerator.AddEnum. The values of string enums are read with their Values method, so
+// the enum methods must have been generated.
+func (g *SchemaGenerator) AddEnum(obj interface{}, fallback string, names ...string) {
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	g.enums = append(g.enums, enum{t: t, names: names, fallback: fallback})
+}
+
+// AddVariant registers the type of the given object as a variant of the interface named iface,
+// encoded with the discriminator member key: value.
+func (g *SchemaGenerator) AddVariant(obj interface{}, iface, key, value string) {
+	g.variants = append(g.variants, newVariant(obj, iface, key, value))
+}
+
+// Run generates the schema document and writes it to out.
+func (g *SchemaGenerator) Run(out io.Writer) error {
+	for len(g.typesUnseen) > 0 {
+		t := g.typesUnseen[0]
+		g.typesUnseen = g.typesUnseen[1:]
+
+		if err := g.genDefinition(t, false); err != nil {
+			return err
+		}
+		if g.hasPartial(t) {
+			if err := g.genDefinition(t, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(schema{"$schema": SchemaDraft, "$defs": g.defs}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(append(data, '\n'))
+	return err
+}
+
+// hasPartial returns whether the struct t has a partial counterpart.
+func (g *SchemaGenerator) hasPartial(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t.Name() != "" && t.PkgPath() == g.pkgPath
+}
+
+// defName returns the name of the definition of the struct t, or of its partial counterpart.
+func (g *SchemaGenerator) defName(t reflect.Type, partial bool) string {
+	if partial && g.hasPartial(t) {
+		return "Partial" + t.Name()
+	}
+	return t.Name()
+}
+
+func (g *SchemaGenerator) ref(t reflect.Type, partial bool) schema {
+	g.addType(t)
+	return schema{"$ref": "#/$defs/" + g.defName(t, partial)}
+}
+
+func (g *SchemaGenerator) genDefinition(t reflect.Type, partial bool) error {
+	name := g.defName(t, partial)
+	if t1, ok := g.defTypes[name]; ok && t1 != t {
+		return fmt.Errorf("cannot generate schema for %v, its name is used by %v", t, t1)
+	}
+	s, err := g.structSchema(t, partial)
+	if err != nil {
+		return err
+	}
+	g.defTypes[name] = t
+	g.defs[name] = s
+	return nil
+}
+
+// structSchema returns the schema of the struct t, or of its partial counterpart. Variants of
+// unions have the discriminator member, which encoders always write.
+func (g *SchemaGenerator) structSchema(t reflect.Type, partial bool) (schema, error) {
+	fs, err := getStructFields(t)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate schema for %v: %v", t, err)
+	}
+
+	props := schema{}
+	var required []string
+	if v, ok := variantOfType(g.variants, t); ok {
+		props[v.key] = schema{"const": v.value}
+		if !partial {
+			required = append(required, v.key)
+		}
+	}
+	for _, f := range fs {
+		tags := parseFieldTags(f)
+		if tags.omit {
+			continue
+		}
+		name := g.fieldNamer.GetJSONFieldName(t, f)
+		s, err := g.fieldSchema(f, tags, partial)
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate schema for field %v of %v: %v", f.Name, t, err)
+		}
+		props[name] = s
+		if tags.required || !partial && hasValidateRule(f, "required") {
+			required = append(required, name)
+		}
+	}
+
+	ret := schema{"type": "object", "properties": props}
+	if len(required) > 0 {
+		ret["required"] = required
+	}
+	if g.disallowUnknownFields {
+		ret["additionalProperties"] = false
+	}
+	return ret, nil
+}
+
+// fieldSchema returns the schema of the values of the field f. Values of partials may be set to
+// null, unless the field is required.
+func (g *SchemaGenerator) fieldSchema(f reflect.StructField, tags fieldTags, partial bool) (schema, error) {
+	if tags.encrypt != "" {
+		s := schema{"type": "string", "contentEncoding": "base64"}
+		if partial && !tags.required {
+			s = nullable(s)
+		}
+		return s, nil
+	}
+
+	// The validate rules apply to the values pointers point to.
+	t, null := f.Type, partial && !tags.required
+	for t.Kind() == reflect.Ptr && customEncoders[t.String()] == "" {
+		t, null = t.Elem(), true
+	}
+	s, err := g.typeSchema(t, tags, partial)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateKeywords(s, t, f.Tag.Get("validate")); err != nil {
+		return nil, err
+	}
+	if null {
+		s = nullable(s)
+	}
+	return s, nil
+}
+
+// typeSchema returns the schema of values of the type t, as genTypeEncoder encodes them.
+func (g *SchemaGenerator) typeSchema(t reflect.Type, tags fieldTags, partial bool) (schema, error) {
+	switch {
+	case hasTimeFormat(t, tags):
+		return timeSchema(t, tags)
+	case t == timeType:
+		return schema{"type": "string", "format": "date-time"}, nil
+	}
+	if e, ok := g.enum(t); ok {
+		return enumSchema(e)
+	}
+	if v, ok := optionalValue(t); ok {
+		// Optional types such as opt.Int are written as their values or null.
+		s, err := g.typeSchema(v, tags, partial)
+		if err != nil {
+			return nil, err
+		}
+		return nullable(s), nil
+	}
+	if t.Kind() == reflect.Struct && t.Name() != "" && !hasCustomMarshaler(t) {
+		return g.ref(t, partial), nil
+	}
+
+	switch {
+	case reflect.PtrTo(t).Implements(reflect.TypeOf((*partialencode.Marshaler)(nil)).Elem()),
+		reflect.PtrTo(t).Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()):
+		// Values written by custom marshalers are not known.
+		return schema{}, nil
+	case reflect.PtrTo(t).Implements(reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()):
+		return schema{"type": "string"}, nil
+	}
+
+	switch t.String() {
+	case "json.Number":
+		return schema{"type": "number"}, nil
+	case "*big.Int":
+		return schema{"type": []string{"integer", "null"}}, nil
+	case "*big.Float":
+		return schema{"type": []string{"number", "null"}}, nil
+	}
+
+	unsigned, integer := integerKinds[t.Kind()]
+	switch {
+	case tags.asString && primitiveStringEncoders[t.Kind()] != "":
+		return schema{"type": "string"}, nil
+	case t.Kind() == reflect.Bool:
+		return schema{"type": "boolean"}, nil
+	case t.Kind() == reflect.String:
+		return schema{"type": "string"}, nil
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return schema{"type": "number"}, nil
+	case integer && unsigned || t.Kind() == reflect.Uintptr:
+		return schema{"type": "integer", "minimum": 0}, nil
+	case integer:
+		return schema{"type": "integer"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		var s schema
+		if t.Elem().Kind() == reflect.Uint8 && t.Elem().Name() == "uint8" {
+			s = schema{"type": "string", "contentEncoding": "base64"}
+		} else {
+			items, err := g.typeSchema(t.Elem(), tags, partial)
+			if err != nil {
+				return nil, err
+			}
+			s = schema{"type": "array", "items": items}
+			if t.Kind() == reflect.Array {
+				s["minItems"], s["maxItems"] = t.Len(), t.Len()
+			}
+		}
+		if t.Kind() == reflect.Slice {
+			s = nullable(s)
+		}
+		return s, nil
+
+	case reflect.Map:
+		key := t.Key()
+		if _, ok := primitiveStringEncoders[key.Kind()]; !ok && !hasCustomMarshaler(key) {
+			return nil, fmt.Errorf("map key type %v not supported: only string and integer keys and types implementing Marshaler interfaces are allowed", key)
+		}
+		values, err := g.typeSchema(t.Elem(), tags, partial)
+		if err != nil {
+			return nil, err
+		}
+		s := schema{"type": "object", "additionalProperties": values}
+		if unsigned, ok := integerKinds[key.Kind()]; ok && !hasCustomMarshaler(key) {
+			pattern := "^-?[0-9]+$"
+			if unsigned {
+				pattern = "^[0-9]+$"
+			}
+			s["propertyNames"] = schema{"pattern": pattern}
+		}
+		return nullable(s), nil
+
+	case reflect.Ptr:
+		s, err := g.typeSchema(t.Elem(), tags, partial)
+		if err != nil {
+			return nil, err
+		}
+		return nullable(s), nil
+
+	case reflect.Struct:
+		// Anonymous structs are inlined; they have partial counterparts too.
+		return g.structSchema(t, partial)
+
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return schema{}, nil
+		}
+		return g.unionSchema(t, tags, partial)
+	}
+	return nil, fmt.Errorf("don't know how to encode %v", t)
+}
+
+// unionSchema returns the schema of an interface with variants. The discriminators of variants
+// of partials are optional, so any of them may match.
+func (g *SchemaGenerator) unionSchema(t reflect.Type, tags fieldTags, partial bool) (schema, error) {
+	vs := variantsOf(g.variants, t.Name())
+	if len(vs) == 0 {
+		return nil, fmt.Errorf("interface type %v not supported: only interface{} and interfaces with variants are allowed", t)
+	}
+	var alts []schema
+	for _, v := range vs {
+		if tags.union != "" && tags.union != v.key {
+			return nil, fmt.Errorf("variant %v of %v uses discriminator %q, not %q", v.t, t, v.key, tags.union)
+		}
+		alts = append(alts, g.ref(v.t, partial))
+	}
+	if partial {
+		return nullable(schema{"anyOf": alts}), nil
+	}
+	return nullable(schema{"oneOf": alts}), nil
+}
+
+func (g *SchemaGenerator) enum(t reflect.Type) (enum, bool) {
+	for _, e := range g.enums {
+		if e.t == t {
+			return e, true
+		}
+	}
+	return enum{}, false
+}
+
+// enumSchema returns the schema of an enum: string enums are encoded by their values and
+// integer enums by the names of their constants. Enums with a fallback accept any string.
+func enumSchema(e enum) (schema, error) {
+	if e.fallback != "" {
+		return schema{"type": "string"}, nil
+	}
+	if e.t.Kind() != reflect.String {
+		return schema{"type": "string", "enum": e.names}, nil
+	}
+
+	m, ok := e.t.MethodByName("Values")
+	if !ok {
+		return nil, fmt.Errorf("cannot generate schema for enum %v, it has no Values method", e.t)
+	}
+	vs := m.Func.Call([]reflect.Value{reflect.Zero(e.t)})[0]
+	values := make([]string, vs.Len())
+	for i := range values {
+		values[i] = vs.Index(i).String()
+	}
+	return schema{"type": "string", "enum": values}, nil
+}
+
+// timeSchema returns the schema of times and durations in the format set with the partial tag.
+func timeSchema(t reflect.Type, tags fieldTags) (schema, error) {
+	if t == durationType {
+		if tags.durationFormat == "string" {
+			return schema{"type": "string"}, nil
+		}
+		return schema{"type": "integer"}, nil
+	}
+
+	switch layout, err := timeLayout(tags.timeFormat); {
+	case err != nil:
+		return nil, err
+	case tags.timeFormat == "unix" || tags.timeFormat == "unixms":
+		return schema{"type": "integer"}, nil
+	case layout == time.RFC3339 || layout == time.RFC3339Nano:
+		return schema{"type": "string", "format": "date-time"}, nil
+	}
+	return schema{"type": "string"}, nil
+}
+
+// nullable returns the schema s that also allows null.
+func nullable(s schema) schema {
+	switch typ := s["type"].(type) {
+	case string:
+		s["type"] = []string{typ, "null"}
+	case []string:
+		for _, t := range typ {
+			if t == "null" {
+				return s
+			}
+		}
+		s["type"] = append(typ, "null")
+	default:
+		if len(s) == 0 {
+			return s
+		}
+		// Null is added as another alternative of unions.
+		for _, key := range []string{"anyOf", "oneOf"} {
+			if alts, ok := s[key].([]schema); ok && len(s) == 1 {
+				for _, alt := range alts {
+					if alt["type"] == "null" {
+						return s
+					}
+				}
+				s[key] = append(alts, schema{"type": "null"})
+				return s
+			}
+		}
+		return schema{"anyOf": []schema{s, {"type": "null"}}}
+	}
+	// Enums are either the values of enum types or the ones of oneof validate rules.
+	switch e := s["enum"].(type) {
+	case []string:
+		values := make([]interface{}, 0, len(e)+1)
+		for _, v := range e {
+			values = append(values, v)
+		}
+		s["enum"] = append(values, nil)
+	case []interface{}:
+		for _, v := range e {
+			if v == nil {
+				return s
+			}
+		}
+		s["enum"] = append(e, nil)
+	}
+	return s
+}
+
+// validateRules returns the rules of the validate tag of the field f that apply to the field
+// itself, rather than to its elements.
+func validateRules(tag string) []string {
+	var ret []string
+	for _, r := range strings.Split(tag, ",") {
+		r = strings.TrimSpace(r)
+		if r == "dive" {
+			break
+		}
+		if r != "" {
+			ret = append(ret, r)
+		}
+	}
+	return ret
+}
+
+func hasValidateRule(f reflect.StructField, rule string) bool {
+	for _, r := range validateRules(f.Tag.Get("validate")) {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// validateFormats maps rules of the validate tag to formats.
+var validateFormats = map[string]string{
+	"email":    "email",
+	"url":      "uri",
+	"uri":      "uri",
+	"uuid":     "uuid",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"hostname": "hostname",
+}
+
+// validateKeywords adds the keywords the validate tag maps to to the schema s of values of the
+// type t.
+func validateKeywords(s schema, t reflect.Type, tag string) error {
+	for _, r := range validateRules(tag) {
+		name, param := r, ""
+		if i := strings.IndexByte(r, '='); i >= 0 {
+			name, param = r[:i], r[i+1:]
+		}
+		if format, ok := validateFormats[name]; ok {
+			s["format"] = format
+			continue
+		}
+
+		var number json.Number
+		switch name {
+		case "min", "max", "len", "gt", "gte", "lt", "lte":
+			if _, err := strconv.ParseFloat(param, 64); err != nil {
+				return fmt.Errorf("invalid validate rule %q", r)
+			}
+			number = json.Number(param)
+		}
+
+		switch name {
+		case "oneof":
+			var values []interface{}
+			for _, v := range strings.Fields(param) {
+				if t.Kind() == reflect.String {
+					values = append(values, v)
+				} else if _, err := strconv.ParseFloat(v, 64); err == nil {
+					values = append(values, json.Number(v))
+				} else {
+					return fmt.Errorf("invalid validate rule %q", r)
+				}
+			}
+			s["enum"] = values
+		case "min", "max", "len":
+			var prefix string
+			switch t.Kind() {
+			case reflect.String:
+				prefix = "Length"
+			case reflect.Slice, reflect.Array:
+				prefix = "Items"
+			case reflect.Map:
+				prefix = "Properties"
+			default:
+				if name != "len" {
+					s[name+"imum"] = number
+				}
+				continue
+			}
+			if name != "max" {
+				s["min"+prefix] = number
+			}
+			if name != "min" {
+				s["max"+prefix] = number
+			}
+		case "gt":
+			s["exclusiveMinimum"] = number
+		case "gte":
+			s["minimum"] = number
+		case "lt":
+			s["exclusiveMaximum"] = number
+		case "lte":
+			s["maximum"] = number
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaColor string
+
+func (schemaColor) Values() []schemaColor { return []schemaColor{"red", "green"} }
+
+type schemaShape interface{ Area() float64 }
+
+type schemaCircle struct {
+	R float64 `json:"r" validate:"gt=0"`
+}
+
+func (*schemaCircle) Area() float64 { return 0 }
+
+type schemaAddress struct {
+	City string `json:"city" validate:"required,min=1,max=64"`
+}
+
+type schemaUser struct {
+	Name     string            `json:"name,required"`
+	Email    *string           `json:"email" validate:"omitempty,email"`
+	Age      uint8             `json:"age" validate:"lte=150"`
+	ID       int64             `json:"id,string"`
+	Color    schemaColor       `json:"color"`
+	Tags     []string          `json:"tags" validate:"max=3,dive,min=1"`
+	Scores   map[int]float64   `json:"scores"`
+	Address  schemaAddress     `json:"address"`
+	Shape    schemaShape       `json:"shape"`
+	Created  time.Time         `json:"created"`
+	Expires  time.Time         `json:"expires" partial:"time=unix"`
+	Token    string            `json:"token" partial:"encrypt=pii"`
+	Data     []byte            `json:"data"`
+	Any      interface{}       `json:"any"`
+	Internal string            `json:"-"`
+	Labels   map[string]string `json:"labels" validate:"min=1"`
+	Plan     string            `json:"plan" validate:"oneof=free pro"`
+	Level    *int              `json:"level" validate:"oneof=1 2"`
+}
+
+func TestSchema(t *testing.T) {
+	g := NewSchemaGenerator()
+	g.SetPkg("gen", reflect.TypeOf(schemaUser{}).PkgPath())
+	g.AddEnum(schemaColor(""), "", "colorRed", "colorGreen")
+	g.AddVariant(schemaCircle{}, "schemaShape", "type", "circle")
+	g.Add(schemaUser{})
+
+	var out bytes.Buffer
+	if err := g.Run(&out); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Schema string                                `json:"$schema"`
+		Defs   map[string]map[string]json.RawMessage `json:"$defs"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Schema != SchemaDraft {
+		t.Errorf("$schema = %q", doc.Schema)
+	}
+
+	var names []string
+	for name := range doc.Defs {
+		names = append(names, name)
+	}
+	for _, name := range []string{"schemaUser", "PartialschemaUser", "schemaAddress", "PartialschemaAddress", "schemaCircle", "PartialschemaCircle"} {
+		if doc.Defs[name] == nil {
+			t.Errorf("no definition of %s in %q", name, names)
+		}
+	}
+
+	for i, test := range []struct {
+		Def, Property, Want string
+	}{
+		{"schemaUser", "name", `{"type":"string"}`},
+		{"PartialschemaUser", "name", `{"type":"string"}`},
+		{"schemaUser", "email", `{"format":"email","type":["string","null"]}`},
+		{"PartialschemaUser", "email", `{"format":"email","type":["string","null"]}`},
+		{"schemaUser", "age", `{"maximum":150,"minimum":0,"type":"integer"}`},
+		{"PartialschemaUser", "age", `{"maximum":150,"minimum":0,"type":["integer","null"]}`},
+		{"schemaUser", "id", `{"type":"string"}`},
+		{"schemaUser", "color", `{"enum":["red","green"],"type":"string"}`},
+		{"PartialschemaUser", "color", `{"enum":["red","green",null],"type":["string","null"]}`},
+		{"schemaUser", "tags", `{"items":{"type":"string"},"maxItems":3,"type":["array","null"]}`},
+		{"schemaUser", "scores", `{"additionalProperties":{"type":"number"},"propertyNames":{"pattern":"^-?[0-9]+$"},"type":["object","null"]}`},
+		{"schemaUser", "address", `{"$ref":"#/$defs/schemaAddress"}`},
+		{"PartialschemaUser", "address", `{"anyOf":[{"$ref":"#/$defs/PartialschemaAddress"},{"type":"null"}]}`},
+		{"schemaUser", "shape", `{"oneOf":[{"$ref":"#/$defs/schemaCircle"},{"type":"null"}]}`},
+		{"PartialschemaUser", "shape", `{"anyOf":[{"$ref":"#/$defs/PartialschemaCircle"},{"type":"null"}]}`},
+		{"schemaUser", "created", `{"format":"date-time","type":"string"}`},
+		{"schemaUser", "expires", `{"type":"integer"}`},
+		{"schemaUser", "token", `{"contentEncoding":"base64","type":"string"}`},
+		{"schemaUser", "data", `{"contentEncoding":"base64","type":["string","null"]}`},
+		{"schemaUser", "any", `{}`},
+		{"schemaUser", "labels", `{"additionalProperties":{"type":"string"},"minProperties":1,"type":["object","null"]}`},
+		{"schemaUser", "plan", `{"enum":["free","pro"],"type":"string"}`},
+		{"PartialschemaUser", "plan", `{"enum":["free","pro",null],"type":["string","null"]}`},
+		{"schemaUser", "level", `{"enum":[1,2,null],"type":["integer","null"]}`},
+		{"PartialschemaUser", "level", `{"enum":[1,2,null],"type":["integer","null"]}`},
+		{"schemaUser", "-", ``},
+		{"schemaUser", "Internal", ``},
+		{"schemaUser", "required", `["name"]`},
+		{"PartialschemaUser", "required", `["name"]`},
+		{"schemaAddress", "required", `["city"]`},
+		{"PartialschemaAddress", "required", ``},
+		{"schemaAddress", "city", `{"maxLength":64,"minLength":1,"type":"string"}`},
+		{"schemaCircle", "type", `{"const":"circle"}`},
+		{"schemaCircle", "r", `{"exclusiveMinimum":0,"type":"number"}`},
+		{"schemaCircle", "required", `["type"]`},
+		{"PartialschemaCircle", "required", ``},
+	} {
+		def := doc.Defs[test.Def]
+		got := def["required"]
+		if test.Property != "required" {
+			var props map[string]json.RawMessage
+			if err := json.Unmarshal(def["properties"], &props); err != nil {
+				t.Fatal(err)
+			}
+			got = props[test.Property]
+		}
+		var b bytes.Buffer
+		if got != nil {
+			if err := json.Compact(&b, got); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if b.String() != test.Want {
+			t.Errorf("[%d] %s.%s = %s; want %s", i, test.Def, test.Property, b.String(), test.Want)
+		}
+	}
+}
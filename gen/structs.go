@@ -10,6 +10,10 @@ import (
 const PartialValidKey = "PartialValid"
 const PartialSetKey = "PartialSet"
 
+// PartialOfTag is the option of the partial tag of the PartialValid field that names the type
+// the struct is the partial of, since partial names are not always the model name prefixed.
+const PartialOfTag = "of"
+
 func (g *PartialGenerator) getStructName(t reflect.Type) string {
 	return g.structName("Partial", t)
 }
@@ -35,11 +39,12 @@ func (g *PartialGenerator) genStructPartialStruct(t reflect.Type) error {
 	sname := g.getStructName(t)
 	bname := g.getBoolStructName(t)
 
+	g.genVariantComment(t)
 	fmt.Fprintln(g.out, "type "+sname+" struct {")
 	for i := 0; i < t.NumField(); i++ {
 		g.genFieldPartialStruct(t.Field(i), 1)
 	}
-	fmt.Fprintln(g.out, "  "+PartialValidKey+" "+bname+"`bson:\"-\" json:\"-\"`")
+	fmt.Fprintln(g.out, "  "+PartialValidKey+" "+bname+"`bson:\"-\" json:\"-\" partial:\""+PartialOfTag+"="+t.Name()+"\"`")
 	fmt.Fprintln(g.out, "  "+PartialSetKey+" "+bname+"`bson:\"-\" json:\"-\"`")
 	fmt.Fprintln(g.out, "}")
 	fmt.Fprintln(g.out, "")
@@ -55,7 +60,7 @@ func (g *PartialGenerator) genFieldPartialStruct(f reflect.StructField, indent i
 		g.genTypePartial(f.Type, indent+1)
 	}
 	if len(string(f.Tag)) > 0 {
-		fmt.Fprintln(g.out, " `"+string(f.Tag)+"`")
+		fmt.Fprintln(g.out, " "+escapeTag(f.Tag))
 	} else {
 		fmt.Fprintln(g.out, "")
 	}
@@ -104,7 +109,16 @@ func (g *PartialGenerator) genTypePartial(t reflect.Type, indent int) {
 			}
 			fmt.Fprintln(g.out, ws+"  } `bson:\"-\" json:\"-\"`")
 			fmt.Fprint(g.out, ws+"}")
+		case reflect.Interface:
+			if t.Name() == "" {
+				fmt.Fprint(g.out, t.String())
+			}
 		}
+	} else if t.PkgPath() == g.pkgPath && t.Kind() == reflect.Interface && len(variantsOf(g.variants, t.Name())) > 0 {
+		fmt.Fprint(g.out, partialUnionName(t.Name()))
+	} else if t.PkgPath() == g.pkgPath && t.Kind() != reflect.Struct {
+		// Only structs have partial counterparts.
+		fmt.Fprint(g.out, t.Name())
 	} else if t.PkgPath() == g.pkgPath {
 		fmt.Fprint(g.out, g.getStructName(t))
 	} else {
@@ -0,0 +1,107 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of time.Time and time.Duration values are set with the partial tag:
+//
+//	`partial:"time=unix|unixms|rfc3339|rfc3339nano|layout:<layout>"`
+//	`partial:"duration=string|ms|ns"`
+//
+// The formats are applied to the elements of slices, arrays, maps and pointers too.
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// hasTimeFormat returns whether t is encoded in a format set with the partial tag.
+func hasTimeFormat(t reflect.Type, tags fieldTags) bool {
+	return t == timeType && tags.timeFormat != "" || t == durationType && tags.durationFormat != ""
+}
+
+// timeLayout returns the layout of a time format, or an empty string for numeric formats.
+func timeLayout(format string) (string, error) {
+	switch {
+	case format == "unix" || format == "unixms":
+		return "", nil
+	case format == "rfc3339":
+		return time.RFC3339, nil
+	case format == "rfc3339nano":
+		return time.RFC3339Nano, nil
+	case strings.HasPrefix(format, "layout:"):
+		layout := strings.TrimPrefix(format, "layout:")
+		if layout == "" || strings.ContainsAny(layout, "\"\\") || strings.IndexFunc(layout, func(r rune) bool { return r < ' ' }) >= 0 {
+			return "", fmt.Errorf("invalid time layout %q: it must be non-empty and must not need escaping in JSON", layout)
+		}
+		return layout, nil
+	}
+	return "", fmt.Errorf("unknown time format %q", format)
+}
+
+func (g *Generator) genTimeEncoder(t reflect.Type, in string, tags fieldTags, indent int) error {
+	ws := strings.Repeat("  ", indent)
+
+	if t == durationType {
+		switch tags.durationFormat {
+		case "string":
+			fmt.Fprintln(g.out, ws+"out.Duration("+in+")")
+		case "ms":
+			fmt.Fprintln(g.out, ws+"out.DurationMilli("+in+")")
+		case "ns":
+			fmt.Fprintln(g.out, ws+"out.Int64(int64("+in+"))")
+		default:
+			return fmt.Errorf("unknown duration format %q", tags.durationFormat)
+		}
+		return nil
+	}
+
+	layout, err := timeLayout(tags.timeFormat)
+	switch {
+	case err != nil:
+		return err
+	case tags.timeFormat == "unix":
+		fmt.Fprintln(g.out, ws+"out.UnixTime("+in+")")
+	case tags.timeFormat == "unixms":
+		fmt.Fprintln(g.out, ws+"out.UnixMilliTime("+in+")")
+	default:
+		fmt.Fprintln(g.out, ws+"out.TimeLayout("+in+", "+strconv.Quote(layout)+")")
+	}
+	return nil
+}
+
+func (g *Generator) genTimeDecoder(t reflect.Type, out string, tags fieldTags, indent int) error {
+	ws := strings.Repeat("  ", indent)
+
+	if t == durationType {
+		switch tags.durationFormat {
+		case "string":
+			fmt.Fprintln(g.out, ws+out+" = in.Duration()")
+		case "ms":
+			fmt.Fprintln(g.out, ws+out+" = in.DurationMilli()")
+		case "ns":
+			fmt.Fprintln(g.out, ws+out+" = "+g.getType(t)+"(in.Int64())")
+		default:
+			return fmt.Errorf("unknown duration format %q", tags.durationFormat)
+		}
+		return nil
+	}
+
+	layout, err := timeLayout(tags.timeFormat)
+	switch {
+	case err != nil:
+		return err
+	case tags.timeFormat == "unix":
+		fmt.Fprintln(g.out, ws+out+" = in.UnixTime()")
+	case tags.timeFormat == "unixms":
+		fmt.Fprintln(g.out, ws+out+" = in.UnixMilliTime()")
+	default:
+		fmt.Fprintln(g.out, ws+out+" = in.TimeLayout("+strconv.Quote(layout)+")")
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Tracked requests to generate Tracked<T> types that record which fields of a struct are
+// written through them.
+func (g *PartialGenerator) Tracked() {
+	g.tracked = true
+}
+
+func (g *PartialGenerator) getTrackedName(t reflect.Type) string {
+	return g.structName("Tracked", t)
+}
+
+// typeString returns the Go expression of the type t.
+func (g *PartialGenerator) typeString(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" || t.PkgPath() == g.pkgPath {
+			return t.Name()
+		}
+		return g.pkgAlias(t.PkgPath()) + "." + t.Name()
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + g.typeString(t.Elem())
+	case reflect.Slice:
+		return "[]" + g.typeString(t.Elem())
+	case reflect.Array:
+		return "[" + strconv.Itoa(t.Len()) + "]" + g.typeString(t.Elem())
+	case reflect.Map:
+		return "map[" + g.typeString(t.Key()) + "]" + g.typeString(t.Elem())
+	case reflect.Struct:
+		var fields []string
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			s := g.typeString(f.Type)
+			if !f.Anonymous {
+				s = f.Name + " " + s
+			}
+			if f.Tag != "" {
+				s += " " + strconv.Quote(string(f.Tag))
+			}
+			fields = append(fields, s)
+		}
+		return "struct {" + strings.Join(fields, "; ") + "}"
+	}
+	return t.String()
+}
+
+// trackedNested returns whether changes of the field f are tracked by a nested tracker, which
+// is the case for local structs and pointers to them.
+func (g *PartialGenerator) trackedNested(f reflect.StructField) bool {
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return g.hasPartial(t)
+}
+
+// genTracked generates the tracker of the struct t. The tracker records the fields written
+// through its setters in a partial bool struct, and keeps trackers of nested structs to record
+// the changes of their fields.
+func (g *PartialGenerator) genTracked(t reflect.Type) {
+	tname := g.getTrackedName(t)
+	sname := g.getStructName(t)
+	bname := g.getBoolStructName(t)
+
+	var fs []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.PkgPath == "" && !f.Anonymous {
+			fs = append(fs, f)
+		}
+	}
+
+	fmt.Fprintf(g.out, "// %s records which fields of a %s are written through it, see Changes.\n", tname, t.Name())
+	fmt.Fprintln(g.out, "type "+tname+" struct {")
+	fmt.Fprintln(g.out, "  v *"+t.Name())
+	fmt.Fprintln(g.out, "  changed "+bname)
+	for _, f := range fs {
+		if g.trackedNested(f) {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			fmt.Fprintln(g.out, "  nested"+f.Name+" "+g.getTrackedName(ft))
+		}
+	}
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	fmt.Fprintf(g.out, "// New%s returns a tracker of the writes to v.\n", tname)
+	fmt.Fprintln(g.out, "func New"+tname+"(v *"+t.Name()+") *"+tname+" {")
+	fmt.Fprintln(g.out, "  return &"+tname+"{v: v}")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	fmt.Fprintln(g.out, "// Value returns the tracked struct. Writes to it that bypass the tracker are not recorded.")
+	fmt.Fprintln(g.out, "func (t *"+tname+") Value() *"+t.Name()+" {")
+	fmt.Fprintln(g.out, "  return t.v")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	for _, f := range fs {
+		fmt.Fprintf(g.out, "// Set%s sets %s and records the change.\n", f.Name, f.Name)
+		fmt.Fprintln(g.out, "func (t *"+tname+") Set"+f.Name+"(v "+g.typeString(f.Type)+") {")
+		fmt.Fprintln(g.out, "  t.v."+f.Name+" = v")
+		fmt.Fprintln(g.out, "  t.changed."+f.Name+" = true")
+		fmt.Fprintln(g.out, "}")
+		fmt.Fprintln(g.out)
+
+		if !g.trackedNested(f) {
+			continue
+		}
+		ft := f.Type
+		ref := "&t.v." + f.Name
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+			ref = "t.v." + f.Name
+		}
+		fmt.Fprintf(g.out, "// Track%s returns the tracker of %s, which records the writes to its fields.\n", f.Name, f.Name)
+		if f.Type.Kind() == reflect.Ptr {
+			fmt.Fprintf(g.out, "// A nil %s is allocated first, which is recorded as a change of %s.\n", f.Name, f.Name)
+		}
+		fmt.Fprintln(g.out, "func (t *"+tname+") Track"+f.Name+"() *"+g.getTrackedName(ft)+" {")
+		if f.Type.Kind() == reflect.Ptr {
+			fmt.Fprintln(g.out, "  if t.v."+f.Name+" == nil {")
+			fmt.Fprintln(g.out, "    t.v."+f.Name+" = new("+g.typeString(ft)+")")
+			fmt.Fprintln(g.out, "    t.changed."+f.Name+" = true")
+			fmt.Fprintln(g.out, "  }")
+		}
+		fmt.Fprintln(g.out, "  t.nested"+f.Name+".v = "+ref)
+		fmt.Fprintln(g.out, "  return &t.nested"+f.Name)
+		fmt.Fprintln(g.out, "}")
+		fmt.Fprintln(g.out)
+	}
+
+	fmt.Fprintln(g.out, "// Changed returns whether any field was written since the last Commit.")
+	fmt.Fprintln(g.out, "func (t *"+tname+") Changed() bool {")
+	fmt.Fprint(g.out, "  return t.changed != ("+bname+"{})")
+	for _, f := range fs {
+		if g.trackedNested(f) {
+			fmt.Fprint(g.out, " || t.nested"+f.Name+".Changed()")
+		}
+	}
+	fmt.Fprintln(g.out)
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	fmt.Fprintln(g.out, "// Changes returns a partial with the fields written since the last Commit. Fields of")
+	fmt.Fprintln(g.out, "// nested structs written through their trackers are included as nested partials.")
+	fmt.Fprintln(g.out, "func (t *"+tname+") Changes() "+sname+" {")
+	fmt.Fprintln(g.out, "  var p "+sname)
+	for _, f := range fs {
+		fmt.Fprintln(g.out, "  if t.changed."+f.Name+" {")
+		g.genToPartial(f.Type, "t.v."+f.Name, "p."+f.Name, 2)
+		g.genPartialFlag(f, "t.v."+f.Name, "p", 2)
+		if g.trackedNested(f) {
+			fmt.Fprintln(g.out, "  } else if t.nested"+f.Name+".Changed() {")
+			if f.Type.Kind() == reflect.Ptr {
+				vVar := g.uniqueVarName()
+				fmt.Fprintln(g.out, "    "+vVar+" := t.nested"+f.Name+".Changes()")
+				fmt.Fprintln(g.out, "    p."+f.Name+" = &"+vVar)
+			} else {
+				fmt.Fprintln(g.out, "    p."+f.Name+" = t.nested"+f.Name+".Changes()")
+			}
+			fmt.Fprintln(g.out, "    p."+PartialValidKey+"."+f.Name+" = true")
+		}
+		fmt.Fprintln(g.out, "  }")
+	}
+	fmt.Fprintln(g.out, "  return p")
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+
+	fmt.Fprintln(g.out, "// Commit resets the tracking, e.g. after the changes are persisted.")
+	fmt.Fprintln(g.out, "func (t *"+tname+") Commit() {")
+	fmt.Fprintln(g.out, "  t.changed = "+bname+"{}")
+	for _, f := range fs {
+		if g.trackedNested(f) {
+			fmt.Fprintln(g.out, "  t.nested"+f.Name+".Commit()")
+		}
+	}
+	fmt.Fprintln(g.out, "}")
+	fmt.Fprintln(g.out)
+}
@@ -0,0 +1,260 @@
+package gen
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Unions.
+//
+// An interface-typed field holds one of the variants of the interface: struct types marked with
+// "//partialencode:variant Iface key=value". A variant is encoded as an object with the
+// discriminator member "key": "value", which selects the type when the field is decoded.
+//
+// Partial structs hold the partial counterparts of the variants in an interface generated for
+// the union, Partial<Iface>, and the partial variants are marked as its variants in turn. A
+// value decoded without a discriminator patches the partial variant the field already holds,
+// keeping its type.
+
+// variant is a type registered as a variant of an interface.
+type variant struct {
+	t     reflect.Type
+	iface string // Name of the interface.
+	key   string // Name of the discriminator member.
+	value string // Value of the discriminator member.
+}
+
+// AddVariant registers the type of the given object as a variant of the interface named iface,
+// encoded with the discriminator member key: value.
+func (g *PartialGenerator) AddVariant(obj interface{}, iface, key, value string) {
+	g.variants = append(g.variants, newVariant(obj, iface, key, value))
+}
+
+// AddVariant registers the type of the given object as a variant of the interface named iface,
+// encoded with the discriminator member key: value.
+func (g *Generator) AddVariant(obj interface{}, iface, key, value string) {
+	g.variants = append(g.variants, newVariant(obj, iface, key, value))
+}
+
+func newVariant(obj interface{}, iface, key, value string) variant {
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return variant{t: t, iface: iface, key: key, value: value}
+}
+
+// variantsOf returns the variants of the interface named iface.
+func variantsOf(vs []variant, iface string) []variant {
+	var ret []variant
+	for _, v := range vs {
+		if v.iface == iface {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+// variantOfType returns the registration of the variant type t.
+func variantOfType(vs []variant, t reflect.Type) (variant, bool) {
+	for _, v := range vs {
+		if v.t == t {
+			return v, true
+		}
+	}
+	return variant{}, false
+}
+
+// partialUnionName returns the name of the interface of the partial variants of iface.
+func partialUnionName(iface string) string {
+	return "Partial" + iface
+}
+
+// genPartialUnions generates the interfaces holding partial variants and their implementations.
+func (g *PartialGenerator) genPartialUnions() {
+	var ifaces []string
+	for _, v := range g.variants {
+		if len(variantsOf(g.variants, v.iface)) > 0 && !containsString(ifaces, v.iface) {
+			ifaces = append(ifaces, v.iface)
+		}
+	}
+
+	for _, iface := range ifaces {
+		name := partialUnionName(iface)
+		method := iface + "Variant"
+
+		var types []string
+		for _, v := range variantsOf(g.variants, iface) {
+			types = append(types, "*"+g.getStructName(v.t))
+		}
+
+		fmt.Fprintf(g.out, "// %s holds a partial variant of %s: %s.\n", name, iface, strings.Join(types, ", "))
+		fmt.Fprintln(g.out, "type "+name+" interface {")
+		fmt.Fprintln(g.out, "  "+method+"() string")
+		fmt.Fprintln(g.out, "}")
+		fmt.Fprintln(g.out)
+
+		for _, v := range variantsOf(g.variants, iface) {
+			fmt.Fprintf(g.out, "// %s returns the discriminator value of the variant.\n", method)
+			fmt.Fprintf(g.out, "func (*%s) %s() string { return %q }\n", g.getStructName(v.t), method, v.value)
+			fmt.Fprintln(g.out)
+		}
+	}
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// genVariantComment marks the partial struct of a variant as a variant of the partial union.
+func (g *PartialGenerator) genVariantComment(t reflect.Type) {
+	if v, ok := variantOfType(g.variants, t); ok {
+		fmt.Fprintf(g.out, "//partialencode:variant %s %s=%s\n", partialUnionName(v.iface), v.key, v.value)
+	}
+}
+
+// genObjectStart generates the start of the object encoding of the struct t. The discriminator
+// of a variant is written first.
+func (g *Generator) genObjectStart(t reflect.Type, ws string) {
+	v, ok := variantOfType(g.variants, t)
+	if !ok {
+		fmt.Fprintln(g.out, ws+"out.RawByte('{')")
+		fmt.Fprintln(g.out, ws+"first := true")
+		fmt.Fprintln(g.out, ws+"_ = first")
+		return
+	}
+	fmt.Fprintf(g.out, ws+"out.RawString(%q)\n", "{"+strconv.Quote(v.key)+":"+strconv.Quote(v.value))
+	fmt.Fprintln(g.out, ws+"first := false")
+	fmt.Fprintln(g.out, ws+"_ = first")
+}
+
+// genDiscriminatorCase generates skipping of the discriminator by the decoder of the struct t
+// if it is a variant.
+func (g *Generator) genDiscriminatorCase(t reflect.Type, fs []reflect.StructField) error {
+	v, ok := variantOfType(g.variants, t)
+	if !ok {
+		return nil
+	}
+	for _, f := range fs {
+		if !parseFieldTags(f).omit && g.fieldNamer.GetJSONFieldName(t, f) == v.key {
+			return fmt.Errorf("field %v of %v conflicts with the discriminator %q", f.Name, t, v.key)
+		}
+	}
+	fmt.Fprintf(g.out, "    case %q:\n", v.key)
+	fmt.Fprintln(g.out, "      in.SkipRecursive()")
+	return nil
+}
+
+// unionVariants returns the variants of the interface t, checking them against the union tag.
+func (g *Generator) unionVariants(t reflect.Type, tags fieldTags) ([]variant, error) {
+	vs := variantsOf(g.variants, t.Name())
+	if len(vs) == 0 {
+		return nil, fmt.Errorf("interface type %v not supported: only interface{} and interfaces with variants are allowed", t)
+	}
+	for _, v := range vs {
+		if !reflect.PtrTo(v.t).Implements(t) {
+			return nil, fmt.Errorf("variant %v does not implement %v", v.t, t)
+		}
+		if tags.union != "" && tags.union != v.key {
+			return nil, fmt.Errorf("variant %v of %v uses discriminator %q, not %q", v.t, t, v.key, tags.union)
+		}
+	}
+	return vs, nil
+}
+
+func (g *Generator) genUnionEncoder(t reflect.Type, in string, tags fieldTags, indent int) error {
+	ws := strings.Repeat("  ", indent)
+	vs, err := g.unionVariants(t, tags)
+	if err != nil {
+		return err
+	}
+
+	vVar := g.uniqueVarName()
+	fmt.Fprintln(g.out, ws+"switch "+vVar+" := ("+in+").(type) {")
+	for _, v := range vs {
+		fmt.Fprintln(g.out, ws+"case *"+g.getType(v.t)+":")
+		fmt.Fprintln(g.out, ws+"  if "+vVar+" == nil {")
+		fmt.Fprintln(g.out, ws+`    out.RawString("null")`)
+		fmt.Fprintln(g.out, ws+"  } else {")
+		if err := g.genTypeEncoder(v.t, "*"+vVar, fieldTags{}, indent+2); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, ws+"  }")
+	}
+	fmt.Fprintln(g.out, ws+"case nil:")
+	fmt.Fprintln(g.out, ws+`  out.RawString("null")`)
+	fmt.Fprintln(g.out, ws+"default:")
+	fmt.Fprintln(g.out, ws+"  out.Raw(json.Marshal("+vVar+"))")
+	fmt.Fprintln(g.out, ws+"}")
+	return nil
+}
+
+func (g *Generator) genUnionDecoder(t reflect.Type, out string, tags fieldTags, indent int) error {
+	ws := strings.Repeat("  ", indent)
+	vs, err := g.unionVariants(t, tags)
+	if err != nil {
+		return err
+	}
+	key := vs[0].key
+	for _, v := range vs {
+		if v.key != key {
+			return fmt.Errorf("variants of %v use different discriminators %q and %q", t, key, v.key)
+		}
+	}
+
+	dVar := g.uniqueVarName()
+	vVar := g.uniqueVarName()
+	fmt.Fprintln(g.out, ws+"if in.IsNull() {")
+	fmt.Fprintln(g.out, ws+"  in.Skip()")
+	fmt.Fprintln(g.out, ws+"  "+out+" = nil")
+	fmt.Fprintf(g.out, ws+"} else if %s := in.Discriminator(%q); %s != \"\" {\n", dVar, key, dVar)
+	fmt.Fprintln(g.out, ws+"  switch "+dVar+" {")
+	for _, v := range vs {
+		typ := g.getType(v.t)
+		fmt.Fprintf(g.out, ws+"  case %q:\n", v.value)
+		fmt.Fprintln(g.out, ws+"    "+vVar+", _ := ("+out+").(*"+typ+")")
+		fmt.Fprintln(g.out, ws+"    if "+vVar+" == nil {")
+		fmt.Fprintln(g.out, ws+"      "+vVar+" = new("+typ+")")
+		fmt.Fprintln(g.out, ws+"    }")
+		if err := g.genTypeDecoder(v.t, "*"+vVar, fieldTags{}, indent+2); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, ws+"    "+out+" = "+vVar)
+	}
+	fmt.Fprintln(g.out, ws+"  default:")
+	fmt.Fprintln(g.out, ws+"    in.AddError(&jlexer.LexerError{")
+	fmt.Fprintln(g.out, ws+"      Offset: in.GetPos(),")
+	fmt.Fprintf(g.out, ws+"      Reason: %q,\n", "unknown variant of "+t.Name())
+	fmt.Fprintln(g.out, ws+"      Data:   string([]byte("+dVar+")),")
+	fmt.Fprintln(g.out, ws+"    })")
+	fmt.Fprintln(g.out, ws+"  }")
+	fmt.Fprintln(g.out, ws+"} else {")
+	fmt.Fprintln(g.out, ws+"  // A value without the discriminator patches the current variant.")
+	fmt.Fprintln(g.out, ws+"  switch "+vVar+" := ("+out+").(type) {")
+	for _, v := range vs {
+		fmt.Fprintln(g.out, ws+"  case *"+g.getType(v.t)+":")
+		fmt.Fprintln(g.out, ws+"    if "+vVar+" == nil {")
+		fmt.Fprintln(g.out, ws+"      "+vVar+" = new("+g.getType(v.t)+")")
+		fmt.Fprintln(g.out, ws+"      "+out+" = "+vVar)
+		fmt.Fprintln(g.out, ws+"    }")
+		if err := g.genTypeDecoder(v.t, "*"+vVar, fieldTags{}, indent+2); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(g.out, ws+"  default:")
+	fmt.Fprintln(g.out, ws+"    in.AddError(&jlexer.LexerError{")
+	fmt.Fprintln(g.out, ws+"      Offset: in.GetPos(),")
+	fmt.Fprintf(g.out, ws+"      Reason: %q,\n", "missing discriminator of "+t.Name())
+	fmt.Fprintf(g.out, ws+"      Data:   %q,\n", key)
+	fmt.Fprintln(g.out, ws+"    })")
+	fmt.Fprintln(g.out, ws+"  }")
+	fmt.Fprintln(g.out, ws+"}")
+	return nil
+}
@@ -0,0 +1,120 @@
+package gen
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type variantShape interface{ Area() float64 }
+
+type variantCircle struct {
+	R float64 `json:"r"`
+}
+
+func (*variantCircle) Area() float64 { return 0 }
+
+type variantSquare struct {
+	Side float64 `json:"side"`
+}
+
+func (*variantSquare) Area() float64 { return 0 }
+
+type variantLine struct {
+	Length float64 `json:"length"`
+}
+
+type variantTyped struct {
+	Type string `json:"type"`
+}
+
+func (*variantTyped) Area() float64 { return 0 }
+
+type variantTagged struct {
+	Shape variantShape `json:"shape" partial:"union=kind"`
+}
+
+type variantUntagged struct {
+	Shape variantShape `json:"shape"`
+}
+
+type variantDrawing struct {
+	Shape  variantShape   `json:"shape" partial:"union=type"`
+	Shapes []variantShape `json:"shapes"`
+}
+
+func TestUnionErrors(t *testing.T) {
+	for i, test := range []struct {
+		Variants [][3]interface{} // Object, discriminator key and value.
+		Obj      interface{}
+		Error    string
+	}{
+		{
+			Obj:   variantUntagged{},
+			Error: "interface type gen.variantShape not supported",
+		},
+		{
+			Variants: [][3]interface{}{{variantLine{}, "type", "line"}},
+			Obj:      variantUntagged{},
+			Error:    "variant gen.variantLine does not implement gen.variantShape",
+		},
+		{
+			Variants: [][3]interface{}{{variantCircle{}, "type", "circle"}},
+			Obj:      variantTagged{},
+			Error:    `variant gen.variantCircle of gen.variantShape uses discriminator "type", not "kind"`,
+		},
+		{
+			Variants: [][3]interface{}{{variantCircle{}, "type", "circle"}, {variantSquare{}, "kind", "square"}},
+			Obj:      variantUntagged{},
+			Error:    `variants of gen.variantShape use different discriminators "type" and "kind"`,
+		},
+		{
+			Variants: [][3]interface{}{{variantTyped{}, "type", "typed"}},
+			Obj:      variantUntagged{},
+			Error:    `field Type of gen.variantTyped conflicts with the discriminator "type"`,
+		},
+	} {
+		g := NewGenerator("variant_test.go")
+		g.SetPkg("gen", reflect.TypeOf(variantDrawing{}).PkgPath())
+		for _, v := range test.Variants {
+			g.AddVariant(v[0], "variantShape", v[1].(string), v[2].(string))
+			g.Add(v[0])
+		}
+		g.Add(test.Obj)
+
+		err := g.Run(&bytes.Buffer{})
+		if err == nil || !strings.Contains(err.Error(), test.Error) {
+			t.Errorf("[%d] Run() error = %v; want %q", i, err, test.Error)
+		}
+	}
+}
+
+func TestUnionDecoder(t *testing.T) {
+	g := NewGenerator("variant_test.go")
+	g.SetPkg("gen", reflect.TypeOf(variantDrawing{}).PkgPath())
+	g.AddVariant(variantCircle{}, "variantShape", "type", "circle")
+	g.AddVariant(variantSquare{}, "variantShape", "type", "square")
+	g.Add(variantCircle{})
+	g.Add(variantSquare{})
+	g.Add(variantDrawing{})
+
+	var out bytes.Buffer
+	if err := g.Run(&out); err != nil {
+		t.Fatal(err)
+	}
+	code := out.String()
+	for _, want := range []string{
+		`in.Discriminator("type")`,
+		`case "circle":`,
+		`case "square":`,
+		`"unknown variant of variantShape"`,
+		`"missing discriminator of variantShape"`,
+		`out.RawString("{\"type\":\"circle\"")`,
+		`out.RawString("{\"type\":\"square\"")`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code does not contain %s", want)
+		}
+	}
+}
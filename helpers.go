@@ -3,7 +3,6 @@ package partialencode
 
 import (
 	"io"
-	"io/ioutil"
 	"net/http"
 	"strconv"
 
@@ -16,6 +15,12 @@ type Marshaler interface {
 	MarshalPartialJSON(w *jwriter.Writer)
 }
 
+// FullMarshaler is implemented by generated types that can be encoded with all of their fields,
+// regardless of which of them are valid.
+type FullMarshaler interface {
+	MarshalFullJSON(w *jwriter.Writer)
+}
+
 // Marshaler is an partialencode-compatible unmarshaler interface.
 type Unmarshaler interface {
 	UnMarshalPartialJSON(w *jlexer.Lexer)
@@ -26,6 +31,12 @@ type Optional interface {
 	IsDefined() bool
 }
 
+// Defaulter is implemented by partial structs with default values. Generated decoders call
+// ApplyDefaults after decoding an object to set the fields absent from it.
+type Defaulter interface {
+	ApplyDefaults()
+}
+
 // Marshal returns data as a single byte slice. Method is suboptimal as the data is likely to be copied
 // from a chain of smaller chunks.
 func Marshal(v Marshaler) ([]byte, error) {
@@ -34,6 +45,28 @@ func Marshal(v Marshaler) ([]byte, error) {
 	return w.BuildBytes()
 }
 
+// MarshalView returns data encoded in the view, see jwriter.Writer.View, as a single byte slice.
+func MarshalView(v Marshaler, view string) ([]byte, error) {
+	w := jwriter.Writer{View: view}
+	v.MarshalPartialJSON(&w)
+	return w.BuildBytes()
+}
+
+// MarshalRedacted returns data encoded with the values of sensitive fields replaced by
+// jwriter.DefaultPlaceholder, e.g. for logs, as a single byte slice.
+func MarshalRedacted(v Marshaler) ([]byte, error) {
+	w := jwriter.Writer{RedactSensitive: jwriter.RedactPlaceholder}
+	v.MarshalPartialJSON(&w)
+	return w.BuildBytes()
+}
+
+// MarshalFull returns data encoded with all of its fields as a single byte slice.
+func MarshalFull(v FullMarshaler) ([]byte, error) {
+	w := jwriter.Writer{}
+	v.MarshalFullJSON(&w)
+	return w.BuildBytes()
+}
+
 // MarshalToWriter marshals the data to an io.Writer.
 func MarshalToWriter(v Marshaler, w io.Writer) (written int, err error) {
 	jw := jwriter.Writer{}
@@ -59,6 +92,24 @@ func MarshalToHTTPResponseWriter(v Marshaler, w http.ResponseWriter) (started bo
 	return
 }
 
+// MarshalToHTTPResponseWriterStream sets the Content-Type header for the http.ResponseWriter
+// and streams the data to the writer as it is produced. Content-Length is not set, so the
+// response uses chunked transfer encoding and the payload is never fully held in memory.
+// started will be equal to false if an error occurred before any data was sent (in this case
+// a 500 reply is possible).
+func MarshalToHTTPResponseWriterStream(v Marshaler, w http.ResponseWriter) (started bool, written int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+
+	jw := jwriter.NewStreamWriter(w, jwriter.DefaultStreamThreshold)
+	v.MarshalPartialJSON(jw)
+	if jw.Error != nil && jw.Written() == 0 {
+		return false, 0, jw.Error
+	}
+
+	err = jw.Flush()
+	return true, jw.Written(), err
+}
+
 // Unmarshal decodes the JSON in data into the object.
 func Unmarshal(data []byte, v Unmarshaler) error {
 	l := jlexer.Lexer{Data: data}
@@ -66,13 +117,10 @@ func Unmarshal(data []byte, v Unmarshaler) error {
 	return l.Error()
 }
 
-// UnmarshalFromReader reads all the data in the reader and decodes as JSON into the object.
+// UnmarshalFromReader decodes JSON read from the reader into the object. The input is
+// streamed, so the whole document is never kept in memory.
 func UnmarshalFromReader(r io.Reader, v Unmarshaler) error {
-	data, err := ioutil.ReadAll(r)
-	if err != nil {
-		return err
-	}
-	l := jlexer.Lexer{Data: data}
+	l := jlexer.Lexer{Reader: r}
 	v.UnMarshalPartialJSON(&l)
 	return l.Error()
 }
@@ -0,0 +1,63 @@
+package partialencode
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/reddyvinod/partialencode/jwriter"
+)
+
+type words []string
+
+func (ws words) MarshalPartialJSON(w *jwriter.Writer) {
+	w.RawByte('[')
+	for i, s := range ws {
+		if i > 0 {
+			w.RawByte(',')
+		}
+		w.String(s)
+	}
+	w.RawByte(']')
+}
+
+type failing struct{}
+
+func (failing) MarshalPartialJSON(w *jwriter.Writer) {
+	w.Error = errors.New("marshal failed")
+}
+
+func TestMarshalToHTTPResponseWriterStream(t *testing.T) {
+	ws := make(words, 10000)
+	for i := range ws {
+		ws[i] = "test"
+	}
+	want := `["` + strings.Repeat(`test","`, len(ws)-1) + `test"]`
+
+	rec := httptest.NewRecorder()
+	started, written, err := MarshalToHTTPResponseWriterStream(ws, rec)
+	if !started || err != nil {
+		t.Fatalf("MarshalToHTTPResponseWriterStream() = %v, %v; want started", started, err)
+	}
+	if written != len(want) || rec.Body.String() != want {
+		t.Errorf("MarshalToHTTPResponseWriterStream() wrote %d bytes %q; want %q", written, rec.Body.String(), want)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q; want application/json", got)
+	}
+	if got := rec.Header().Get("Content-Length"); got != "" {
+		t.Errorf("Content-Length = %q; want none", got)
+	}
+}
+
+func TestMarshalToHTTPResponseWriterStreamError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	started, written, err := MarshalToHTTPResponseWriterStream(failing{}, rec)
+	if started || written != 0 || err == nil {
+		t.Errorf("MarshalToHTTPResponseWriterStream() = %v, %v, %v; want error before start", started, written, err)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("MarshalToHTTPResponseWriterStream() wrote %q; want nothing", rec.Body.String())
+	}
+}
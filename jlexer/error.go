@@ -1,6 +1,10 @@
 package jlexer
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // LexerError implements the error interface and represents all possible errors that can be
 // generated during parsing the JSON data.
@@ -8,8 +12,145 @@ type LexerError struct {
 	Reason string
 	Offset int
 	Data   string
+
+	Path   string // Path of the value the error occurred at, e.g. $.items[3].price; empty at the root.
+	Line   int    // 1-based line of Offset.
+	Column int    // 1-based column of Offset, in bytes.
+
+	Expected Kind // Kind of the expected token, if the error is caused by an unexpected token.
+	Actual   Kind // Kind of the token found instead.
 }
 
 func (l *LexerError) Error() string {
-	return fmt.Sprintf("parse error: %s near offset %d of '%s'", l.Reason, l.Offset, l.Data)
+	if l.Path == "" {
+		return fmt.Sprintf("parse error: %s near offset %d of '%s'", l.Reason, l.Offset, l.Data)
+	}
+	loc := " at " + l.Path
+	if l.Line > 0 {
+		loc += fmt.Sprintf(" (line %d, column %d)", l.Line, l.Column)
+	}
+	if l.Expected != Invalid {
+		return fmt.Sprintf("parse error: %s, got %s%s near offset %d of '%s'", l.Reason, l.Actual, loc, l.Offset, l.Data)
+	}
+	return fmt.Sprintf("parse error: %s%s near offset %d of '%s'", l.Reason, loc, l.Offset, l.Data)
+}
+
+// expectedKinds maps the descriptions of expected tokens used in errors to token kinds.
+var expectedKinds = map[string]Kind{
+	"{":           Object,
+	"[":           Array,
+	"}":           ObjectEnd,
+	"]":           ArrayEnd,
+	"string":      String,
+	"number":      Number,
+	"json.Number": Number,
+	"big.Int":     Number,
+	"big.Float":   Number,
+	"bool":        Bool,
+	"null":        Null,
+}
+
+// locateError fills in the path, line and column of the error. Offset of the error is
+// expected to be relative to the start of the input.
+func (r *Lexer) locateError(err *LexerError) *LexerError {
+	err.Path = r.jsonPath()
+	err.Line, err.Column = r.lineColumn(err.Offset)
+	return err
+}
+
+// lineColumn computes the 1-based line and column of the offset in the input. The input is
+// scanned from the offset of the previous call on if it is in the window and not after offset,
+// since errors mostly occur in the order of the input.
+func (r *Lexer) lineColumn(offset int) (line, column int) {
+	line, lineStart, from := r.lines+1, r.lineStart, r.offset
+	if c := r.lineCache; c.line > 0 && c.offset >= r.offset && c.offset <= offset {
+		line, lineStart, from = c.line, c.lineStart, c.offset
+	}
+
+	end := offset
+	if end > r.offset+len(r.Data) {
+		end = r.offset + len(r.Data)
+	}
+	for i := from; i < end; i++ {
+		if r.Data[i-r.offset] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+	if end > from {
+		r.lineCache = lineCache{offset: end, line: line, lineStart: lineStart}
+	}
+	return line, offset - lineStart + 1
+}
+
+// lineCache is the line of an offset computed by lineColumn.
+type lineCache struct {
+	offset    int
+	line      int
+	lineStart int
+}
+
+// AddMissingKeyError reports that the required key is missing from the object that was decoded
+// last. The error is located at the end of the object and has the path of the key.
+func (r *Lexer) AddMissingKeyError(key string) {
+	if r.fatalError != nil {
+		return
+	}
+	err := r.locateError(&LexerError{
+		Reason: "key '" + key + "' is required",
+		Offset: r.start + r.offset,
+	})
+	if err.Path == "" {
+		err.Path = "$"
+	}
+	err.Path += PathKey(key)
+	r.fatalError = err
+}
+
+// PathKey returns the segment of the object key in the $.key[index] notation of the paths of
+// errors: .key for identifiers, ["key"] otherwise.
+func PathKey(key string) string {
+	if isIdentifier(key) {
+		return "." + key
+	}
+	return "[" + strconv.Quote(key) + "]"
+}
+
+// jsonPath returns the path of the value the last fetched token belongs to, in the
+// $.key[index] notation, or an empty string for the root value.
+func (r *Lexer) jsonPath() string {
+	var b strings.Builder
+	for _, f := range r.frames() {
+		if f.object && !f.hasKey || !f.object && f.index < 0 {
+			break
+		}
+		if b.Len() == 0 {
+			b.WriteByte('$')
+		}
+		if f.object {
+			b.WriteString(PathKey(string(f.key)))
+		} else {
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(f.index))
+			b.WriteByte(']')
+		}
+	}
+	return b.String()
+}
+
+// isIdentifier checks whether the key can be written in the dot notation.
+func isIdentifier(key string) bool {
+	if len(key) == 0 {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,202 @@
+package jlexer
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestErrorLocation(t *testing.T) {
+	for i, test := range []struct {
+		toParse    string
+		decode     func(l *Lexer)
+		wantPath   string
+		wantLine   int
+		wantColumn int
+		wantKinds  [2]Kind
+	}{
+		{
+			toParse:    `{"items": [{"price": 1}, {"price": "x"}]}`,
+			decode:     decodeItems,
+			wantPath:   "$.items[1].price",
+			wantLine:   1,
+			wantColumn: 39,
+			wantKinds:  [2]Kind{Number, String},
+		},
+		{
+			toParse:    "{\n  \"items\": [\n    {\"price\": 1},\n    {\"price\": true}\n  ]\n}",
+			decode:     decodeItems,
+			wantPath:   "$.items[1].price",
+			wantLine:   4,
+			wantColumn: 19,
+			wantKinds:  [2]Kind{Number, Bool},
+		},
+		{
+			toParse:    `{"items": {}}`,
+			decode:     decodeItems,
+			wantPath:   "$.items",
+			wantLine:   1,
+			wantColumn: 12,
+			wantKinds:  [2]Kind{Array, Object},
+		},
+		{
+			toParse:    `{"a b": [1, 2, @]}`,
+			decode:     func(l *Lexer) { l.Interface() },
+			wantPath:   `$["a b"][1]`,
+			wantLine:   1,
+			wantColumn: 16,
+		},
+	} {
+		for _, streaming := range []bool{false, true} {
+			l := Lexer{Data: []byte(test.toParse)}
+			if streaming {
+				l = Lexer{Reader: iotest.OneByteReader(strings.NewReader(test.toParse))}
+			}
+			test.decode(&l)
+
+			err, ok := l.Error().(*LexerError)
+			if !ok {
+				t.Errorf("[%d, %v] error = %v; want *LexerError", i, streaming, l.Error())
+				continue
+			}
+			if err.Path != test.wantPath || err.Line != test.wantLine || err.Column != test.wantColumn {
+				t.Errorf("[%d, %v] error at %s:%d:%d; want %s:%d:%d", i, streaming,
+					err.Path, err.Line, err.Column, test.wantPath, test.wantLine, test.wantColumn)
+			}
+			if kinds := [2]Kind{err.Expected, err.Actual}; kinds != test.wantKinds {
+				t.Errorf("[%d, %v] error kinds = %v; want %v", i, streaming, kinds, test.wantKinds)
+			}
+		}
+	}
+}
+
+func TestMultipleErrorsLocation(t *testing.T) {
+	l := Lexer{Data: []byte(`{"items": [{"price": "x"}, {"price": 2}, {"price": null}]}`), UseMultipleErrors: true}
+	decodeItems(&l)
+
+	want := []string{"$.items[0].price", "$.items[2].price"}
+	errs := l.GetNonFatalErrors()
+	if len(errs) != len(want) {
+		t.Fatalf("GetNonFatalErrors() = %v; want %d errors", errs, len(want))
+	}
+	for i, err := range errs {
+		if err.Path != want[i] {
+			t.Errorf("[%d] error path = %s; want %s", i, err.Path, want[i])
+		}
+	}
+}
+
+// decodeItems decodes {"items": [{"price": number}]} like a generated decoder would.
+func decodeItems(l *Lexer) {
+	l.Delim('{')
+	for !l.IsDelim('}') {
+		key := l.UnsafeString()
+		l.WantColon()
+		switch key {
+		case "items":
+			l.Delim('[')
+			for !l.IsDelim(']') {
+				l.Delim('{')
+				for !l.IsDelim('}') {
+					key := l.UnsafeString()
+					l.WantColon()
+					switch key {
+					case "price":
+						l.Float64()
+					default:
+						l.SkipRecursive()
+					}
+					l.WantComma()
+				}
+				l.Delim('}')
+				l.WantComma()
+			}
+			l.Delim(']')
+		default:
+			l.SkipRecursive()
+		}
+		l.WantComma()
+	}
+	l.Delim('}')
+}
+
+func TestErrorMessage(t *testing.T) {
+	for i, test := range []struct {
+		err  LexerError
+		want string
+	}{
+		{
+			err:  LexerError{Reason: "unknown field", Offset: 3, Data: "x"},
+			want: "parse error: unknown field near offset 3 of 'x'",
+		},
+		{
+			// Errors of the root value keep the message without a location.
+			err:  LexerError{Reason: "expected {", Offset: 0, Data: "[]", Line: 1, Column: 1, Expected: Object, Actual: Array},
+			want: "parse error: expected { near offset 0 of '[]'",
+		},
+		{
+			err:  LexerError{Reason: "expected number", Offset: 6, Data: `"x"}`, Path: "$.a", Line: 1, Column: 7, Expected: Number, Actual: String},
+			want: `parse error: expected number, got string at $.a (line 1, column 7) near offset 6 of '"x"}'`,
+		},
+	} {
+		if got := test.err.Error(); got != test.want {
+			t.Errorf("[%d] Error() = %q; want %q", i, got, test.want)
+		}
+	}
+}
+
+func TestMultipleErrorsLines(t *testing.T) {
+	data := "{\"items\": [\n{\"price\": \"a\"},\n{\"price\": 1},\n\n{\"price\": \"b\"}]}"
+	l := Lexer{Data: []byte(data), UseMultipleErrors: true}
+	decodeItems(&l)
+
+	errs := l.GetNonFatalErrors()
+	if len(errs) != 2 || errs[0].Line != 2 || errs[0].Column != 11 || errs[1].Line != 5 || errs[1].Column != 11 {
+		t.Fatalf("GetNonFatalErrors() = %v; want errors at 2:11 and 5:11", errs)
+	}
+
+	// Lines of earlier offsets are computed from the start of the input.
+	if line, column := l.lineColumn(1); line != 1 || column != 2 {
+		t.Errorf("lineColumn(1) = %d:%d; want 1:2", line, column)
+	}
+}
+
+func TestAddMissingKeyError(t *testing.T) {
+	for i, test := range []struct {
+		toParse  string
+		key      string
+		wantPath string
+	}{
+		{toParse: `{}`, key: "id", wantPath: "$.id"},
+		{toParse: `{"a": [1, {}]}`, key: "first name", wantPath: `$.a[1]["first name"]`},
+	} {
+		l := Lexer{Data: []byte(test.toParse)}
+		// Decode the innermost object the way a generated decoder would before checking it.
+		for l.Ok() && l.PeekKind() != ObjectEnd {
+			l.NextToken()
+		}
+		l.Delim('}')
+		l.AddMissingKeyError(test.key)
+
+		err, ok := l.Error().(*LexerError)
+		if !ok || err.Path != test.wantPath || err.Reason != "key '"+test.key+"' is required" {
+			t.Errorf("[%d] error = %v; want missing key at %s", i, l.Error(), test.wantPath)
+		}
+	}
+}
+
+func TestPathKey(t *testing.T) {
+	for i, test := range []struct {
+		In, Want string
+	}{
+		{"city", ".city"},
+		{"_id2", "._id2"},
+		{"2nd", `["2nd"]`},
+		{"odd-key", `["odd-key"]`},
+		{"", `[""]`},
+	} {
+		if got := PathKey(test.In); got != test.Want {
+			t.Errorf("[%d] PathKey(%q) = %s; want %s", i, test.In, got, test.Want)
+		}
+	}
+}
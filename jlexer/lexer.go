@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"strconv"
 	"unicode"
 	"unicode/utf16"
@@ -38,8 +39,18 @@ type token struct {
 }
 
 // Lexer is a JSON lexer: it iterates over JSON tokens in a byte slice.
+//
+// If Reader is set, the lexer works in streaming mode: Data is a sliding window over the
+// input that is refilled from Reader as tokens are consumed, see reader.go for details.
 type Lexer struct {
-	Data []byte // Input data given to the lexer.
+	Data   []byte    // Input data given to the lexer.
+	Reader io.Reader // Input stream; if set, Data holds the unconsumed part of the stream.
+
+	offset    int  // Number of input bytes discarded from the front of Data in streaming mode.
+	eof       bool // Whether Reader has been exhausted.
+	lines     int  // Number of newlines in the discarded input.
+	lineStart int  // Offset of the line the discarded input ends with.
+	lineCache lineCache
 
 	start int   // Start of the current token.
 	pos   int   // Current unscanned position in the input stream.
@@ -48,6 +59,25 @@ type Lexer struct {
 	firstElement bool // Whether current element is the first in array or an object.
 	wantSep      byte // A comma or a colon character, which need to occur before a token.
 
+	path     []pathFrame // Enclosing arrays and objects of the current token, see Path().
+	pathPos  int         // Position of the last token applied to the path plus one.
+	tracking bool        // Whether the path is tracked token by token, see frames().
+	scan     *Lexer      // Lexer scanning the input for the path if it is not tracked.
+
+	// Input limits, see limits.go. Zero means no limit.
+	MaxDepth     int // Maximum nesting depth of arrays and objects.
+	MaxStringLen int // Maximum length of a string literal in the input, in bytes.
+	MaxMembers   int // Maximum number of members of an object.
+	MaxElements  int // Maximum number of elements of an array.
+	MaxInputSize int // Maximum size of the input, in bytes.
+
+	marked bool // Whether the window data starting at mark must be kept on refills.
+	mark   int  // Start of a value being skipped in streaming mode.
+
+	UseNumber bool // Whether Interface returns numbers as json.Number instead of float64.
+
+	Policy *WritePolicy // Roles allowed to write fields of partials, see policy.go. Nil allows all.
+
 	UseMultipleErrors bool          // If we want to use multiple errors.
 	fatalError        error         // Fatal error occurred during lexing. It is usually a syntax error.
 	multipleErrors    []*LexerError // Semantic errors occurred during lexing. Marshalling will be continued after finding this errors.
@@ -55,9 +85,35 @@ type Lexer struct {
 
 // FetchToken scans the input for the next token.
 func (r *Lexer) FetchToken() {
+	if r.MaxInputSize > 0 && !r.checkInputSize() {
+		return
+	}
+	r.fetchToken()
+	if r.token.kind != tokenUndef && r.fatalError == nil {
+		if r.MaxStringLen > 0 && r.token.kind == tokenString && r.pos-r.start-2 > r.MaxStringLen {
+			r.errLimit(ReasonMaxStringLen)
+			return
+		}
+		if r.tracking {
+			r.trackPath()
+		} else if r.Reader != nil || r.limited() {
+			r.startTracking()
+		}
+	}
+}
+
+// fetchToken determines the type of the next token and scans it.
+func (r *Lexer) fetchToken() {
 	r.token.kind = tokenUndef
 	r.start = r.pos
 
+	if r.Reader != nil {
+		r.fillToken()
+		if r.fatalError != nil {
+			return
+		}
+	}
+
 	// Check if r.Data has r.pos element
 	// If it doesn't, it mean corrupted input data
 	if len(r.Data) < r.pos {
@@ -411,11 +467,11 @@ func (r *Lexer) errParse(what string) {
 		} else {
 			str = string(r.Data[r.pos:r.pos+maxErrorContextLen-3]) + "..."
 		}
-		r.fatalError = &LexerError{
+		r.fatalError = r.locateError(&LexerError{
 			Reason: what,
-			Offset: r.pos,
+			Offset: r.pos + r.offset,
 			Data:   str,
-		}
+		})
 	}
 }
 
@@ -424,9 +480,18 @@ func (r *Lexer) errSyntax() {
 }
 
 func (r *Lexer) errInvalidToken(expected string) {
+	r.errInvalidKind(expected, r.kind())
+}
+
+// errInvalidKind reports that the expected token was not found, actual is the kind of the
+// token found instead.
+func (r *Lexer) errInvalidKind(expected string, actual Kind) {
 	if r.fatalError != nil {
 		return
 	}
+	if !r.Ok() {
+		actual = Invalid
+	}
 	if r.UseMultipleErrors {
 		r.pos = r.start
 		r.consume()
@@ -440,9 +505,11 @@ func (r *Lexer) errInvalidToken(expected string) {
 			r.token.kind = tokenDelim
 		}
 		r.addNonfatalError(&LexerError{
-			Reason: fmt.Sprintf("expected %s", expected),
-			Offset: r.start,
-			Data:   string(r.Data[r.start:r.pos]),
+			Reason:   fmt.Sprintf("expected %s", expected),
+			Offset:   r.start,
+			Data:     string(r.Data[r.start:r.pos]),
+			Expected: expectedKinds[expected],
+			Actual:   actual,
 		})
 		return
 	}
@@ -453,15 +520,18 @@ func (r *Lexer) errInvalidToken(expected string) {
 	} else {
 		str = string(r.token.byteValue[:maxErrorContextLen-3]) + "..."
 	}
-	r.fatalError = &LexerError{
-		Reason: fmt.Sprintf("expected %s", expected),
-		Offset: r.pos,
-		Data:   str,
-	}
+	r.fatalError = r.locateError(&LexerError{
+		Reason:   fmt.Sprintf("expected %s", expected),
+		Offset:   r.pos + r.offset,
+		Data:     str,
+		Expected: expectedKinds[expected],
+		Actual:   actual,
+	})
 }
 
+// GetPos returns the current position in the input.
 func (r *Lexer) GetPos() int {
-	return r.pos
+	return r.pos + r.offset
 }
 
 // Delim consumes a token and verifies that it is the given delimiter.
@@ -471,8 +541,9 @@ func (r *Lexer) Delim(c byte) {
 	}
 
 	if !r.Ok() || r.token.delimValue != c {
+		actual := r.kind()
 		r.consume() // errInvalidToken can change token if UseMultipleErrors is enabled.
-		r.errInvalidToken(string([]byte{c}))
+		r.errInvalidKind(string([]byte{c}), actual)
 	} else {
 		r.consume()
 	}
@@ -530,41 +601,60 @@ func (r *Lexer) SkipRecursive() {
 		return
 	}
 
+	if r.limited() {
+		r.skipTokens()
+		return
+	}
+
 	r.consume()
 
 	level := 1
 	inQuotes := false
 	wasEscape := false
 
-	for i, c := range r.Data[r.pos:] {
-		switch {
-		case c == start && !inQuotes:
-			level++
-		case c == end && !inQuotes:
-			level--
-			if level == 0 {
-				r.pos += i + 1
-				return
+	for {
+		for i, c := range r.Data[r.pos:] {
+			switch {
+			case c == start && !inQuotes:
+				level++
+			case c == end && !inQuotes:
+				level--
+				if level == 0 {
+					r.pos += i + 1
+					r.popPath()
+					return
+				}
+			case c == '\\' && inQuotes:
+				wasEscape = !wasEscape
+				continue
+			case c == '"' && inQuotes:
+				inQuotes = wasEscape
+			case c == '"':
+				inQuotes = true
 			}
-		case c == '\\' && inQuotes:
-			wasEscape = !wasEscape
-			continue
-		case c == '"' && inQuotes:
-			inQuotes = wasEscape
-		case c == '"':
-			inQuotes = true
+			wasEscape = false
+		}
+		r.pos = len(r.Data)
+		if r.Reader == nil || r.eof {
+			break
 		}
-		wasEscape = false
+		// The skipped value is kept in the window, so that Raw() can return it.
+		r.fill()
 	}
-	r.pos = len(r.Data)
-	r.fatalError = &LexerError{
+	if r.fatalError != nil {
+		return
+	}
+	r.fatalError = r.locateError(&LexerError{
 		Reason: "EOF reached while skipping array/object or token",
-		Offset: r.pos,
+		Offset: r.pos + r.offset,
 		Data:   string(r.Data[r.pos:]),
-	}
+	})
 }
 
 // Raw fetches the next item recursively as a data slice
+//
+// In streaming mode the returned slice points to the lexer window, so it is only valid until
+// the next token is fetched.
 func (r *Lexer) Raw() []byte {
 	r.SkipRecursive()
 	if !r.Ok() {
@@ -573,10 +663,34 @@ func (r *Lexer) Raw() []byte {
 	return r.Data[r.start:r.pos]
 }
 
+// KeepRaw fetches the next item recursively like Raw, but the returned slice stays valid while
+// the lexer advances: in streaming mode the data is copied, otherwise it points to the input.
+func (r *Lexer) KeepRaw() []byte {
+	data := r.Raw()
+	if r.Reader != nil && data != nil {
+		data = append([]byte(nil), data...)
+	}
+	return data
+}
+
+// SubLexer returns a lexer over data, e.g. a value kept with KeepRaw, with the input limits,
+// UseNumber and Policy of the lexer.
+func (r *Lexer) SubLexer(data []byte) *Lexer {
+	return &Lexer{
+		Data:         data,
+		MaxDepth:     r.MaxDepth,
+		MaxStringLen: r.MaxStringLen,
+		MaxMembers:   r.MaxMembers,
+		MaxElements:  r.MaxElements,
+		UseNumber:    r.UseNumber,
+		Policy:       r.Policy,
+	}
+}
+
 // IsStart returns whether the lexer is positioned at the start
 // of an input string.
 func (r *Lexer) IsStart() bool {
-	return r.pos == 0
+	return r.pos+r.offset == 0
 }
 
 // Consumed reads all remaining bytes from the input, publishing an error if
@@ -586,18 +700,24 @@ func (r *Lexer) Consumed() {
 		return
 	}
 
-	for _, c := range r.Data[r.pos:] {
-		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
-			r.AddError(&LexerError{
-				Reason: "invalid character '" + string(c) + "' after top-level value",
-				Offset: r.pos,
-				Data:   string(r.Data[r.pos:]),
-			})
+	for {
+		for _, c := range r.Data[r.pos:] {
+			if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+				r.AddError(&LexerError{
+					Reason: "invalid character '" + string(c) + "' after top-level value",
+					Offset: r.pos + r.offset,
+					Data:   string(r.Data[r.pos:]),
+				})
+				return
+			}
+
+			r.pos++
+			r.start++
+		}
+		if r.Reader == nil || r.eof {
 			return
 		}
-
-		r.pos++
-		r.start++
+		r.fill()
 	}
 }
 
@@ -619,6 +739,8 @@ func (r *Lexer) unsafeString() (string, []byte) {
 //
 // Warning: returned string may point to the input buffer, so the string should not outlive
 // the input buffer. Intended pattern of usage is as an argument to a switch statement.
+// In streaming mode the input buffer is reused, so the string is only valid until the next
+// token is fetched.
 func (r *Lexer) UnsafeString() string {
 	ret, _ := r.unsafeString()
 	return ret
@@ -656,9 +778,10 @@ func (r *Lexer) Bytes() []byte {
 	ret := make([]byte, base64.StdEncoding.DecodedLen(len(r.token.byteValue)))
 	n, err := base64.StdEncoding.Decode(ret, r.token.byteValue)
 	if err != nil {
-		r.fatalError = &LexerError{
+		r.fatalError = r.locateError(&LexerError{
 			Reason: err.Error(),
-		}
+			Offset: r.start + r.offset,
+		})
 		return nil
 	}
 
@@ -1055,8 +1178,13 @@ func (r *Lexer) Error() error {
 	return r.fatalError
 }
 
+// AddError sets a fatal error unless one occurred before. The location of a *LexerError is
+// filled in, Offset is expected to be given as returned by GetPos.
 func (r *Lexer) AddError(e error) {
 	if r.fatalError == nil {
+		if err, ok := e.(*LexerError); ok && err.Line == 0 {
+			r.locateError(err)
+		}
 		r.fatalError = e
 	}
 }
@@ -1070,6 +1198,13 @@ func (r *Lexer) AddNonFatalError(e error) {
 }
 
 func (r *Lexer) addNonfatalError(err *LexerError) {
+	// Offsets of the errors passed here are relative to the current window.
+	err.Offset += r.offset
+	if r.Reader != nil {
+		// Error data may point into the window, which is reused on refills.
+		err.Data = string([]byte(err.Data))
+	}
+	r.locateError(err)
 	if r.UseMultipleErrors {
 		// We don't want to add errors with the same offset.
 		if len(r.multipleErrors) != 0 && r.multipleErrors[len(r.multipleErrors)-1].Offset == err.Offset {
@@ -1110,6 +1245,79 @@ func (r *Lexer) JsonNumber() json.Number {
 	}
 }
 
+// BigInt fetches a *big.Int from a number or a string literal. Null is returned as nil.
+func (r *Lexer) BigInt() *big.Int {
+	s, data := r.bigNumber("big.Int")
+	if s == "" {
+		return nil
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		r.addNonfatalError(&LexerError{
+			Offset: r.start,
+			Reason: "invalid integer",
+			Data:   data,
+		})
+		return nil
+	}
+	return n
+}
+
+// BigFloat fetches a *big.Float from a number or a string literal. Null is returned as nil.
+// The precision of the result is large enough to hold the decimal literal exactly.
+func (r *Lexer) BigFloat() *big.Float {
+	s, data := r.bigNumber("big.Float")
+	if s == "" {
+		return nil
+	}
+	// A decimal digit takes less than 4 bits.
+	prec := uint(4*len(s) + 64)
+	n, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+	if err != nil {
+		r.addNonfatalError(&LexerError{
+			Offset: r.start,
+			Reason: err.Error(),
+			Data:   data,
+		})
+		return nil
+	}
+	return n
+}
+
+// bigNumber fetches the literal of a number or a string token for BigInt and BigFloat. The
+// literal is empty for null and on errors; data is the token for error reports.
+func (r *Lexer) bigNumber(expected string) (s, data string) {
+	if r.token.kind == tokenUndef && r.Ok() {
+		r.FetchToken()
+	}
+	if !r.Ok() {
+		r.errInvalidToken(expected)
+		return "", ""
+	}
+
+	switch r.token.kind {
+	case tokenString:
+		s, b := r.unsafeString()
+		if s == "" && r.Ok() {
+			r.addNonfatalError(&LexerError{
+				Offset: r.start,
+				Reason: "empty number",
+				Data:   string(b),
+			})
+		}
+		return s, string(b)
+	case tokenNumber:
+		s := r.number()
+		return s, s
+	case tokenNull:
+		r.Null()
+		return "", ""
+	default:
+		r.errInvalidToken(expected)
+		return "", ""
+	}
+}
+
 // Interface fetches an interface{} analogous to the 'encoding/json' package.
 func (r *Lexer) Interface() interface{} {
 	if r.token.kind == tokenUndef && r.Ok() {
@@ -1123,6 +1331,9 @@ func (r *Lexer) Interface() interface{} {
 	case tokenString:
 		return r.String()
 	case tokenNumber:
+		if r.UseNumber {
+			return json.Number(r.number())
+		}
 		return r.Float64()
 	case tokenBool:
 		return r.Bool()
@@ -3,6 +3,7 @@ package jlexer
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"testing"
 )
@@ -313,6 +314,66 @@ func TestJsonNumber(t *testing.T) {
 	}
 }
 
+func TestInterfaceUseNumber(t *testing.T) {
+	l := Lexer{Data: []byte(`{"id": 12345678901234567890, "a": [1.50, "x"]}`), UseNumber: true}
+
+	got := l.Interface()
+	want := map[string]interface{}{
+		"id": json.Number("12345678901234567890"),
+		"a":  []interface{}{json.Number("1.50"), "x"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Interface() = %v; want %v", got, want)
+	}
+	if err := l.Error(); err != nil {
+		t.Errorf("Interface() error: %v", err)
+	}
+}
+
+func TestBigNumbers(t *testing.T) {
+	for i, test := range []struct {
+		toParse   string
+		wantInt   string
+		wantFloat string
+		wantError bool
+	}{
+		{toParse: `12345678901234567890123`, wantInt: "12345678901234567890123", wantFloat: "1.2345678901234567890123e+22"},
+		{toParse: `"-98765432109876543210"`, wantInt: "-98765432109876543210", wantFloat: "-9.876543210987654321e+19"},
+		{toParse: `0.1`, wantInt: "<nil>", wantFloat: "0.1", wantError: true},
+		{toParse: `"1e-30"`, wantInt: "<nil>", wantFloat: "1e-30", wantError: true},
+		{toParse: `null`, wantInt: "<nil>", wantFloat: "<nil>"},
+
+		{toParse: `""`, wantInt: "<nil>", wantFloat: "<nil>", wantError: true},
+		{toParse: `"a"`, wantInt: "<nil>", wantFloat: "<nil>", wantError: true},
+		{toParse: `true`, wantInt: "<nil>", wantFloat: "<nil>", wantError: true},
+		{toParse: `[1]`, wantInt: "<nil>", wantFloat: "<nil>", wantError: true},
+	} {
+		l := Lexer{Data: []byte(test.toParse)}
+		got := l.BigInt()
+		if s := fmt.Sprint(got); s != test.wantInt {
+			t.Errorf("[%d, %q] BigInt() = %v; want %v", i, test.toParse, s, test.wantInt)
+		}
+		if err := l.Error(); err != nil && !test.wantError {
+			t.Errorf("[%d, %q] BigInt() error: %v", i, test.toParse, err)
+		}
+
+		l = Lexer{Data: []byte(test.toParse)}
+		gotFloat := l.BigFloat()
+		s := "<nil>"
+		if gotFloat != nil {
+			s = gotFloat.Text('g', -1)
+		}
+		if s != test.wantFloat {
+			t.Errorf("[%d, %q] BigFloat() = %v; want %v", i, test.toParse, s, test.wantFloat)
+		}
+		if err := l.Error(); err != nil && test.wantFloat != "<nil>" {
+			t.Errorf("[%d, %q] BigFloat() error: %v", i, test.toParse, err)
+		} else if err == nil && test.wantFloat == "<nil>" && test.wantError {
+			t.Errorf("[%d, %q] BigFloat() ok; want error", i, test.toParse)
+		}
+	}
+}
+
 func TestFetchStringUnterminatedString(t *testing.T) {
 	for _, test := range []struct {
 		data []byte
@@ -0,0 +1,70 @@
+package jlexer
+
+// Input limits.
+//
+// The Max* fields of the Lexer protect decoders from hostile input: deeply nested values that
+// make generated decoders recurse, huge strings, objects and arrays, and unbounded streams.
+// A limit that is exceeded stops lexing with a fatal *LexerError with one of the reasons
+// below, even if UseMultipleErrors is set. When any of the nesting, string or count limits is
+// set, SkipRecursive walks skipped values token by token, so that they are checked as well.
+
+// Reasons of the errors reported when input limits are exceeded.
+const (
+	ReasonMaxDepth     = "maximum nesting depth exceeded"
+	ReasonMaxStringLen = "maximum string length exceeded"
+	ReasonMaxMembers   = "maximum number of object members exceeded"
+	ReasonMaxElements  = "maximum number of array elements exceeded"
+	ReasonMaxInputSize = "maximum input size exceeded"
+)
+
+// errLimit reports that an input limit was exceeded at the current token.
+func (r *Lexer) errLimit(reason string) {
+	if r.fatalError != nil {
+		return
+	}
+	end := r.start + maxErrorContextLen
+	if end > len(r.Data) {
+		end = len(r.Data)
+	}
+	r.fatalError = r.locateError(&LexerError{
+		Reason: reason,
+		Offset: r.start + r.offset,
+		Data:   string(r.Data[r.start:end]),
+	})
+}
+
+// checkInputSize checks that the input read so far does not exceed MaxInputSize.
+func (r *Lexer) checkInputSize() bool {
+	if r.offset+len(r.Data) <= r.MaxInputSize {
+		return true
+	}
+	if r.fatalError == nil {
+		r.fatalError = r.locateError(&LexerError{
+			Reason: ReasonMaxInputSize,
+			Offset: r.MaxInputSize,
+		})
+	}
+	return false
+}
+
+// limited returns whether skipped values have to be checked against the limits.
+func (r *Lexer) limited() bool {
+	return r.MaxDepth > 0 || r.MaxStringLen > 0 || r.MaxMembers > 0 || r.MaxElements > 0
+}
+
+// skipTokens skips the array or object started by the current token by fetching its tokens,
+// which applies the limits to its contents. The skipped value stays available to Raw.
+func (r *Lexer) skipTokens() {
+	start := r.start
+	depth := len(r.path)
+
+	r.marked, r.mark = true, start
+	r.consume()
+	for r.Ok() && len(r.path) >= depth {
+		r.NextToken()
+	}
+	r.marked = false
+
+	// Refills have moved the value to the start of the window.
+	r.start = r.mark
+}
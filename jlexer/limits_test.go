@@ -0,0 +1,108 @@
+package jlexer
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// repeatReader endlessly repeats the given data after the prefix.
+type repeatReader struct {
+	prefix string
+	data   string
+	pos    int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if r.prefix != "" {
+			c := copy(p[n:], r.prefix)
+			r.prefix = r.prefix[c:]
+			n += c
+			continue
+		}
+		c := copy(p[n:], r.data[r.pos:])
+		r.pos = (r.pos + c) % len(r.data)
+		n += c
+	}
+	return n, nil
+}
+
+func TestLimits(t *testing.T) {
+	for i, test := range []struct {
+		toParse    string
+		reader     io.Reader
+		lexer      Lexer
+		skip       bool
+		wantReason string
+	}{
+		{toParse: strings.Repeat("[", 10000), lexer: Lexer{MaxDepth: 100}, wantReason: ReasonMaxDepth},
+		{toParse: strings.Repeat("[", 10000), lexer: Lexer{MaxDepth: 100}, skip: true, wantReason: ReasonMaxDepth},
+		{toParse: strings.Repeat(`{"a":`, 10000), lexer: Lexer{MaxDepth: 100}, wantReason: ReasonMaxDepth},
+		{toParse: `[` + strings.Repeat("[", 99) + strings.Repeat("]", 99) + `]`, lexer: Lexer{MaxDepth: 100}},
+
+		{toParse: `"` + strings.Repeat("a", 1000) + `"`, lexer: Lexer{MaxStringLen: 100}, wantReason: ReasonMaxStringLen},
+		{toParse: `["` + strings.Repeat("a", 1000) + `"]`, lexer: Lexer{MaxStringLen: 100}, skip: true, wantReason: ReasonMaxStringLen},
+		{toParse: `"` + strings.Repeat("a", 100) + `"`, lexer: Lexer{MaxStringLen: 100}},
+
+		{toParse: `{"a": 1, "b": 2, "c": 3}`, lexer: Lexer{MaxMembers: 2}, wantReason: ReasonMaxMembers},
+		{toParse: `[{"a": 1, "b": 2, "c": 3}]`, lexer: Lexer{MaxMembers: 2}, skip: true, wantReason: ReasonMaxMembers},
+		{toParse: `{"a": 1, "b": 2}`, lexer: Lexer{MaxMembers: 2}},
+
+		{toParse: `[1, 2, 3]`, lexer: Lexer{MaxElements: 2}, wantReason: ReasonMaxElements},
+		{toParse: `[[1, 2, 3]]`, lexer: Lexer{MaxElements: 2}, skip: true, wantReason: ReasonMaxElements},
+		{toParse: `[1, 2]`, lexer: Lexer{MaxElements: 2}},
+
+		{toParse: `[1, 2, 3]`, lexer: Lexer{MaxInputSize: 5}, wantReason: ReasonMaxInputSize},
+		{toParse: `[1, 2, 3]`, lexer: Lexer{MaxInputSize: 9}},
+
+		{reader: &repeatReader{data: "["}, lexer: Lexer{MaxDepth: 100}, wantReason: ReasonMaxDepth},
+		{reader: &repeatReader{prefix: `"`, data: "a"}, lexer: Lexer{MaxStringLen: 100}, wantReason: ReasonMaxStringLen},
+		{reader: &repeatReader{prefix: `[`, data: "1,"}, lexer: Lexer{MaxElements: 1000}, wantReason: ReasonMaxElements},
+		{reader: &repeatReader{prefix: `[`, data: "1,"}, lexer: Lexer{MaxInputSize: 1 << 20}, wantReason: ReasonMaxInputSize},
+		{reader: &repeatReader{prefix: `[`, data: " "}, lexer: Lexer{MaxInputSize: 1 << 20}, wantReason: ReasonMaxInputSize},
+		{reader: &repeatReader{prefix: `[`, data: "[1],"}, lexer: Lexer{MaxInputSize: 1 << 20}, skip: true, wantReason: ReasonMaxInputSize},
+	} {
+		l := test.lexer
+		if test.reader != nil {
+			l.Reader = test.reader
+		} else {
+			l.Data = []byte(test.toParse)
+		}
+
+		if test.skip {
+			l.SkipRecursive()
+		} else {
+			l.Interface()
+		}
+
+		err := l.Error()
+		if test.wantReason == "" {
+			if err != nil {
+				t.Errorf("[%d] error: %v", i, err)
+			}
+			continue
+		}
+		if lerr, ok := err.(*LexerError); !ok || lerr.Reason != test.wantReason {
+			t.Errorf("[%d] error = %v; want %q", i, err, test.wantReason)
+		}
+	}
+}
+
+func TestLimitsRaw(t *testing.T) {
+	value := `{"a": [1, 2, {"b": "` + strings.Repeat("x", 2*readerBufSize) + `"}]}`
+
+	l := Lexer{Reader: strings.NewReader(`[` + value + `]`), MaxDepth: 10}
+	l.Delim('[')
+	got := string(l.Raw())
+	l.WantComma()
+	l.Delim(']')
+
+	if err := l.Error(); err != nil {
+		t.Fatalf("Raw() error: %v", err)
+	}
+	if got != value {
+		t.Errorf("Raw() = %.40q...; want %.40q...", got, value)
+	}
+}
@@ -0,0 +1,49 @@
+package jlexer
+
+// Write authorization.
+//
+// Generated decoders of partial structs call Authorize before decoding a field tagged with
+// partial:"write=role|...", so that a caller can only set the fields its roles may write.
+// Without a Policy every field is writable.
+
+// ReasonForbidden is the reason of the errors reported for fields the policy forbids to write.
+const ReasonForbidden = "field not writable"
+
+// WritePolicy holds the roles of the caller decoding a partial.
+type WritePolicy struct {
+	Roles []string // Roles of the caller.
+	Drop  bool     // Whether forbidden fields are skipped silently instead of reported.
+}
+
+// Allows returns whether any of the roles of the policy is one of the given roles.
+func (p *WritePolicy) Allows(roles ...string) bool {
+	for _, r := range p.Roles {
+		for _, role := range roles {
+			if r == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authorize returns whether the policy of the lexer allows to write the field whose key was
+// read last, which the given roles may write. A forbidden field is reported with a non-fatal
+// error at its path unless the policy drops it; either way the caller skips its value.
+func (r *Lexer) Authorize(roles ...string) bool {
+	if r.Policy == nil || r.Policy.Allows(roles...) {
+		return true
+	}
+	if !r.Policy.Drop && r.Ok() {
+		var key string
+		if path := r.frames(); len(path) > 0 && path[len(path)-1].object {
+			key = string(path[len(path)-1].key)
+		}
+		r.addNonfatalError(&LexerError{
+			Reason: ReasonForbidden,
+			Offset: r.pos,
+			Data:   key,
+		})
+	}
+	return false
+}
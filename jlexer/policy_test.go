@@ -0,0 +1,69 @@
+package jlexer
+
+import (
+	"reflect"
+	"testing"
+)
+
+// decodeAuthorized decodes the members of an object the way generated decoders of partials do,
+// with "role" writable by admins and "name" by everyone, and returns the keys it kept.
+func decodeAuthorized(l *Lexer) []string {
+	var keys []string
+	l.Delim('{')
+	for !l.IsDelim('}') {
+		key := l.UnsafeString()
+		l.WantColon()
+		if key == "role" && !l.Authorize("admin") {
+			l.SkipRecursive()
+			l.WantComma()
+			continue
+		}
+		keys = append(keys, key)
+		l.SkipRecursive()
+		l.WantComma()
+	}
+	l.Delim('}')
+	return keys
+}
+
+func TestAuthorize(t *testing.T) {
+	for i, test := range []struct {
+		policy    *WritePolicy
+		multiple  bool
+		wantKeys  []string
+		wantPaths []string
+	}{
+		{policy: nil, wantKeys: []string{"name", "role", "tag"}},
+		{policy: &WritePolicy{Roles: []string{"owner", "admin"}}, wantKeys: []string{"name", "role", "tag"}},
+		{policy: &WritePolicy{Roles: []string{"owner"}, Drop: true}, wantKeys: []string{"name", "tag"}},
+		{policy: &WritePolicy{Roles: []string{"owner"}}, wantKeys: []string{"name"}, wantPaths: []string{"$.role"}},
+		{policy: &WritePolicy{}, multiple: true, wantKeys: []string{"name", "tag"}, wantPaths: []string{"$.role"}},
+	} {
+		l := Lexer{
+			Data:              []byte(`{"name": "x", "role": {"id": [1]}, "tag": "y"}`),
+			Policy:            test.policy,
+			UseMultipleErrors: test.multiple,
+		}
+		keys := decodeAuthorized(&l)
+		if !reflect.DeepEqual(keys, test.wantKeys) {
+			t.Errorf("[%d] keys = %q; want %q", i, keys, test.wantKeys)
+		}
+
+		var errs []*LexerError
+		if test.multiple {
+			errs = l.GetNonFatalErrors()
+		} else if err := l.Error(); err != nil {
+			errs = append(errs, err.(*LexerError))
+		}
+		var paths []string
+		for _, e := range errs {
+			if e.Reason != ReasonForbidden || e.Data != "role" {
+				t.Errorf("[%d] error = %v; want %q of role", i, e, ReasonForbidden)
+			}
+			paths = append(paths, e.Path)
+		}
+		if !reflect.DeepEqual(paths, test.wantPaths) {
+			t.Errorf("[%d] error paths = %q; want %q", i, paths, test.wantPaths)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package jlexer
+
+import (
+	"bytes"
+	"io"
+)
+
+// Streaming mode.
+//
+// When Lexer.Reader is set, Data is a window over the input stream. Before a token is scanned,
+// the window is refilled until it contains the whole token, so tokens are never split between
+// refills. Input before the start of the current token is discarded and its space is reused,
+// so memory usage is bounded by the size of the largest token (or the largest value passed to
+// Raw) rather than by the size of the input.
+//
+// Slices and strings pointing into the window (Raw, UnsafeString, UnsafeBytes and the values of
+// RawMessage-like types) are only valid until the next token is fetched; decoders that keep
+// them have to copy the data. Error offsets and GetPos are reported relative to the start of
+// the stream.
+
+var newline = []byte{'\n'}
+
+// readerBufSize is the initial size of the window in streaming mode.
+const readerBufSize = 4096
+
+// maxEmptyReads is the number of consecutive empty reads after which the reader is considered
+// broken.
+const maxEmptyReads = 100
+
+// fillToken refills the window until it contains a complete token or the input is exhausted.
+func (r *Lexer) fillToken() {
+	for !r.eof {
+		complete, start := tokenComplete(r.Data[r.pos:])
+		if complete {
+			return
+		}
+		// Fail before reading the whole of an oversized token.
+		if r.MaxStringLen > 0 && len(r.Data)-r.pos-start > r.MaxStringLen+2 {
+			r.errLimit(ReasonMaxStringLen)
+			return
+		}
+		r.fill()
+	}
+}
+
+// fill discards the data before the current token and reads more input into the window,
+// growing it if there is no free space left.
+func (r *Lexer) fill() {
+	discard := r.start
+	if r.marked && r.mark < discard {
+		discard = r.mark
+	}
+	if discard > 0 {
+		// Keep track of the lines for error locations.
+		if n := bytes.Count(r.Data[:discard], newline); n > 0 {
+			r.lines += n
+			r.lineStart = r.offset + bytes.LastIndexByte(r.Data[:discard], '\n') + 1
+		}
+
+		n := copy(r.Data, r.Data[discard:])
+		r.offset += discard
+		r.pos -= discard
+		r.start -= discard
+		r.mark -= discard
+		r.Data = r.Data[:n]
+	}
+
+	if len(r.Data) == cap(r.Data) {
+		size := 2 * cap(r.Data)
+		if size < readerBufSize {
+			size = readerBufSize
+		}
+		buf := make([]byte, len(r.Data), size)
+		copy(buf, r.Data)
+		r.Data = buf
+	}
+
+	for i := 0; i < maxEmptyReads; i++ {
+		n, err := r.Reader.Read(r.Data[len(r.Data):cap(r.Data)])
+		r.Data = r.Data[:len(r.Data)+n]
+		if r.MaxInputSize > 0 && !r.checkInputSize() {
+			r.eof = true
+			return
+		}
+		if err != nil {
+			r.eof = true
+			if err != io.EOF && r.fatalError == nil {
+				r.fatalError = err
+			}
+			return
+		}
+		if n > 0 {
+			return
+		}
+	}
+	r.eof = true
+	if r.fatalError == nil {
+		r.fatalError = io.ErrNoProgress
+	}
+}
+
+// tokenComplete checks whether data, after optional whitespace and separators, contains a whole
+// token. Number and keyword tokens are complete only when followed by a delimiter, since the
+// next read could continue them. The start of the token is returned too.
+func tokenComplete(data []byte) (complete bool, start int) {
+	for i, c := range data {
+		switch c {
+		case ' ', '\t', '\r', '\n', ':', ',':
+			continue
+		case '{', '}', '[', ']':
+			return true, i
+		case '"':
+			ok, _, _ := findStringLen(data[i+1:])
+			return ok, i
+		default:
+			for _, c := range data[i+1:] {
+				if isTokenEnd(c) {
+					return true, i
+				}
+			}
+			return false, i
+		}
+	}
+	return false, len(data)
+}
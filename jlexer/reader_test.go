@@ -0,0 +1,176 @@
+package jlexer
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReaderInterface(t *testing.T) {
+	for i, test := range []string{
+		`"simple string"`,
+		`"\n\t\"\/\\\f\r 😀"`,
+		`12345.678e-3`,
+		`true`,
+		`false`,
+		`null`,
+		`[]`,
+		`{}`,
+		`[1, 2.5, "three", true, false, null, {"a": [1, {"b": "c"}]}]`,
+		`{"key": "value", "nested": {"list": [1, 2, 3], "empty": {}}, "last": null}`,
+		`  [  "padded"  ,  -0.5e10  ]  `,
+	} {
+		want := (&Lexer{Data: []byte(test)}).Interface()
+
+		l := Lexer{Reader: iotest.OneByteReader(strings.NewReader(test))}
+		got := l.Interface()
+		l.Consumed()
+		if err := l.Error(); err != nil {
+			t.Errorf("[%d, %q] Interface() error: %v", i, test, err)
+		} else if !reflect.DeepEqual(got, want) {
+			t.Errorf("[%d, %q] Interface() = %v; want %v", i, test, got, want)
+		}
+	}
+}
+
+func TestReaderRaw(t *testing.T) {
+	value := `{"a": "` + strings.Repeat("x", 3*readerBufSize) + `", "b": [1, 2, "]"]}`
+	data := `[` + value + `, 5]`
+
+	l := Lexer{Reader: iotest.HalfReader(strings.NewReader(data))}
+	l.Delim('[')
+	got := string(l.Raw())
+	l.WantComma()
+	n := l.Int()
+	l.WantComma()
+	l.Delim(']')
+	l.Consumed()
+
+	if err := l.Error(); err != nil {
+		t.Fatalf("Raw() error: %v", err)
+	}
+	if got != value {
+		t.Errorf("Raw() = %q; want %q", got, value)
+	}
+	if n != 5 {
+		t.Errorf("Int() = %v; want 5", n)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	for i, test := range []string{
+		strings.Repeat(" ", 2*readerBufSize) + `[1, 2, x]`,
+		`["` + strings.Repeat("a", readerBufSize) + `", tru]`,
+		`[1, 2, "abc` + strings.Repeat(" ", readerBufSize),
+		`{"a": 1, "b" 2}`,
+	} {
+		dl := Lexer{Data: []byte(test)}
+		dl.Interface()
+		want := dl.Error()
+
+		l := Lexer{Reader: iotest.OneByteReader(strings.NewReader(test))}
+		l.Interface()
+		got := l.Error()
+
+		if _, ok := got.(*LexerError); !ok {
+			t.Errorf("[%d] Interface() error: %v; want *LexerError", i, got)
+		} else if got.(*LexerError).Offset != want.(*LexerError).Offset {
+			t.Errorf("[%d] error offset = %d; want %d", i, got.(*LexerError).Offset, want.(*LexerError).Offset)
+		}
+	}
+}
+
+func TestReaderConsumed(t *testing.T) {
+	for i, test := range []struct {
+		toParse   string
+		wantError bool
+	}{
+		{toParse: `{}` + strings.Repeat(" ", 2*readerBufSize), wantError: false},
+		{toParse: `{}` + strings.Repeat(" ", 2*readerBufSize) + "x", wantError: true},
+	} {
+		l := Lexer{Reader: strings.NewReader(test.toParse)}
+		l.Delim('{')
+		l.Delim('}')
+		l.Consumed()
+
+		err := l.Error()
+		if err != nil && !test.wantError {
+			t.Errorf("[%d] Consumed() error: %v", i, err)
+		} else if err == nil && test.wantError {
+			t.Errorf("[%d] Consumed() ok; want error", i)
+		}
+	}
+}
+
+func TestReaderReadError(t *testing.T) {
+	l := Lexer{Reader: iotest.TimeoutReader(iotest.OneByteReader(strings.NewReader(`[1, 2]`)))}
+	l.Interface()
+
+	if err := l.Error(); err != iotest.ErrTimeout {
+		t.Errorf("Interface() error = %v; want %v", err, iotest.ErrTimeout)
+	}
+}
+
+// arrayReader produces a JSON array of n objects without keeping it in memory.
+type arrayReader struct {
+	n       int
+	started bool
+	buf     bytes.Buffer
+}
+
+func (r *arrayReader) Read(p []byte) (int, error) {
+	for r.buf.Len() < len(p) && r.n >= 0 {
+		switch {
+		case r.n == 0:
+			r.buf.WriteString(`]`)
+		case !r.started:
+			r.buf.WriteString(`[{"id": 1, "name": "item"}`)
+			r.started = true
+		default:
+			r.buf.WriteString(`, {"id": 1, "name": "item"}`)
+		}
+		r.n--
+	}
+	if r.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return r.buf.Read(p)
+}
+
+func BenchmarkReaderLargeArray(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l := Lexer{Reader: &arrayReader{n: 100000}}
+		l.Delim('[')
+		for !l.IsDelim(']') {
+			l.SkipRecursive()
+			l.WantComma()
+		}
+		l.Delim(']')
+		if err := l.Error(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReaderKeepRaw(t *testing.T) {
+	data := `[{"a": 1}, "` + strings.Repeat("x", 2*readerBufSize) + `"]`
+
+	l := Lexer{Reader: iotest.OneByteReader(strings.NewReader(data))}
+	l.Delim('[')
+	got := l.KeepRaw()
+	l.WantComma()
+	l.Raw()
+	l.WantComma()
+	l.Delim(']')
+
+	if err := l.Error(); err != nil {
+		t.Fatalf("KeepRaw() error: %v", err)
+	}
+	if string(got) != `{"a": 1}` {
+		t.Errorf("KeepRaw() = %q; want %q", got, `{"a": 1}`)
+	}
+}
@@ -0,0 +1,88 @@
+package jlexer
+
+import (
+	"strings"
+	"time"
+)
+
+// Time fetches a time.Time in RFC 3339 format, as encoded by time.Time.MarshalJSON.
+func (r *Lexer) Time() time.Time {
+	return r.TimeLayout(time.RFC3339Nano)
+}
+
+// TimeLayout fetches a time.Time from a string formatted with the layout, see time.Parse.
+func (r *Lexer) TimeLayout(layout string) time.Time {
+	s, b := r.unsafeString()
+	if !r.Ok() {
+		return time.Time{}
+	}
+	if strings.Contains(layout, "MST") {
+		// Parsed zone abbreviations are kept by the returned time.
+		s = string(b)
+	}
+
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		r.addNonfatalError(&LexerError{
+			Offset: r.start,
+			Reason: err.Error(),
+			Data:   string(b),
+		})
+	}
+	return t
+}
+
+// UnixTime fetches a time.Time from a number of seconds since the Unix epoch. The time is
+// returned in UTC.
+func (r *Lexer) UnixTime() time.Time {
+	n := r.Int64()
+	if !r.Ok() {
+		return time.Time{}
+	}
+	return time.Unix(n, 0).UTC()
+}
+
+// UnixMilliTime fetches a time.Time from a number of milliseconds since the Unix epoch. The
+// time is returned in UTC.
+func (r *Lexer) UnixMilliTime() time.Time {
+	n := r.Int64()
+	if !r.Ok() {
+		return time.Time{}
+	}
+	return time.Unix(n/1e3, n%1e3*1e6).UTC()
+}
+
+// Duration fetches a time.Duration from a string such as "1h30m", see time.ParseDuration.
+func (r *Lexer) Duration() time.Duration {
+	s, b := r.unsafeString()
+	if !r.Ok() {
+		return 0
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		r.addNonfatalError(&LexerError{
+			Offset: r.start,
+			Reason: err.Error(),
+			Data:   string(b),
+		})
+	}
+	return d
+}
+
+// DurationMilli fetches a time.Duration from a number of milliseconds.
+func (r *Lexer) DurationMilli() time.Duration {
+	n := r.Int64()
+	if !r.Ok() {
+		return 0
+	}
+	if n > int64(1<<63-1)/int64(time.Millisecond) || n < -int64(1<<63-1)/int64(time.Millisecond) {
+		r.addNonfatalError(&LexerError{
+			Offset: r.start,
+			Reason: "duration out of range",
+			Data:   string(r.Data[r.start:r.pos]),
+		})
+		return 0
+	}
+	return time.Duration(n) * time.Millisecond
+}
@@ -0,0 +1,78 @@
+package jlexer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTime(t *testing.T) {
+	at := time.Date(2021, 3, 4, 5, 6, 7, 890000000, time.UTC)
+	for i, test := range []struct {
+		toParse   string
+		fetch     func(l *Lexer) time.Time
+		want      time.Time
+		wantError bool
+	}{
+		{toParse: `"2021-03-04T05:06:07.89Z"`, fetch: (*Lexer).Time, want: at},
+		{toParse: `1614834367`, fetch: (*Lexer).UnixTime, want: at.Truncate(time.Second)},
+		{toParse: `1614834367890`, fetch: (*Lexer).UnixMilliTime, want: at},
+		{toParse: `-1`, fetch: (*Lexer).UnixMilliTime, want: time.Unix(0, -1e6).UTC()},
+		{
+			toParse: `"04.03.2021"`,
+			fetch:   func(l *Lexer) time.Time { return l.TimeLayout("02.01.2006") },
+			want:    at.Truncate(24 * time.Hour),
+		},
+
+		{toParse: `"2021-03-04"`, fetch: (*Lexer).Time, wantError: true},
+		{toParse: `1614834367`, fetch: (*Lexer).Time, wantError: true},
+		{toParse: `"1614834367"`, fetch: (*Lexer).UnixTime, wantError: true},
+		{toParse: `1.5`, fetch: (*Lexer).UnixMilliTime, wantError: true},
+	} {
+		l := Lexer{Data: []byte(test.toParse)}
+		got := test.fetch(&l)
+		if err := l.Error(); err != nil {
+			if !test.wantError {
+				t.Errorf("[%d, %q] error: %v", i, test.toParse, err)
+			}
+			continue
+		}
+		if test.wantError {
+			t.Errorf("[%d, %q] ok; want error", i, test.toParse)
+		}
+		if !got.Equal(test.want) {
+			t.Errorf("[%d, %q] = %v; want %v", i, test.toParse, got, test.want)
+		}
+	}
+}
+
+func TestDuration(t *testing.T) {
+	for i, test := range []struct {
+		toParse   string
+		fetch     func(l *Lexer) time.Duration
+		want      time.Duration
+		wantError bool
+	}{
+		{toParse: `"1h30m"`, fetch: (*Lexer).Duration, want: 90 * time.Minute},
+		{toParse: `"-1.5s"`, fetch: (*Lexer).Duration, want: -1500 * time.Millisecond},
+		{toParse: `1500`, fetch: (*Lexer).DurationMilli, want: 1500 * time.Millisecond},
+
+		{toParse: `"1 hour"`, fetch: (*Lexer).Duration, wantError: true},
+		{toParse: `90`, fetch: (*Lexer).Duration, wantError: true},
+		{toParse: `9223372036854776`, fetch: (*Lexer).DurationMilli, wantError: true},
+	} {
+		l := Lexer{Data: []byte(test.toParse)}
+		got := test.fetch(&l)
+		if err := l.Error(); err != nil {
+			if !test.wantError {
+				t.Errorf("[%d, %q] error: %v", i, test.toParse, err)
+			}
+			continue
+		}
+		if test.wantError {
+			t.Errorf("[%d, %q] ok; want error", i, test.toParse)
+		}
+		if got != test.want {
+			t.Errorf("[%d, %q] = %v; want %v", i, test.toParse, got, test.want)
+		}
+	}
+}
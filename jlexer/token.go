@@ -0,0 +1,305 @@
+package jlexer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Kind is the kind of a JSON token.
+type Kind byte
+
+const (
+	Invalid   Kind = iota // No token, e.g. on a syntax error or at the end of input.
+	Object                // Start of an object: '{'.
+	Array                 // Start of an array: '['.
+	String                // A string literal.
+	Number                // A number literal.
+	Bool                  // A true or false literal.
+	Null                  // The null keyword.
+	ObjectEnd             // End of an object: '}'.
+	ArrayEnd              // End of an array: ']'.
+)
+
+var kindNames = [...]string{
+	Invalid:   "invalid",
+	Object:    "object",
+	Array:     "array",
+	String:    "string",
+	Number:    "number",
+	Bool:      "bool",
+	Null:      "null",
+	ObjectEnd: "end of object",
+	ArrayEnd:  "end of array",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Token is a single JSON token returned by NextToken.
+type Token struct {
+	Kind Kind
+
+	// Value is the unescaped value of a string or the literal of a number. It may point to the
+	// input buffer, so it is only valid until the next token is fetched (see UnsafeString).
+	Value []byte
+
+	Bool   bool // Value of a boolean literal.
+	Offset int  // Offset of the token in the input.
+}
+
+// kind returns the kind of the current token.
+func (r *Lexer) kind() Kind {
+	switch r.token.kind {
+	case tokenString:
+		return String
+	case tokenNumber:
+		return Number
+	case tokenBool:
+		return Bool
+	case tokenNull:
+		return Null
+	case tokenDelim:
+		switch r.token.delimValue {
+		case '{':
+			return Object
+		case '[':
+			return Array
+		case '}':
+			return ObjectEnd
+		case ']':
+			return ArrayEnd
+		}
+	}
+	return Invalid
+}
+
+// PeekKind returns the kind of the next token without consuming it.
+func (r *Lexer) PeekKind() Kind {
+	if r.token.kind == tokenUndef && r.Ok() {
+		r.FetchToken()
+	}
+	if !r.Ok() {
+		return Invalid
+	}
+	return r.kind()
+}
+
+// NextToken consumes the next token and returns it. Separators are handled automatically: a
+// colon is expected after an object key and a comma after a value, so a document can be
+// walked by calling NextToken until an error is reported. A token with Invalid kind is returned
+// on errors and at the end of input.
+func (r *Lexer) NextToken() Token {
+	if !r.tracking {
+		r.startTracking()
+	}
+	if r.token.kind == tokenUndef && r.Ok() {
+		r.FetchToken()
+	}
+	if !r.Ok() {
+		return Token{}
+	}
+
+	t := Token{
+		Kind:   r.kind(),
+		Offset: r.start + r.offset,
+	}
+	switch t.Kind {
+	case String, Number:
+		t.Value = r.token.byteValue
+	case Bool:
+		t.Bool = r.token.boolValue
+	}
+	r.consumeToken(t.Kind)
+	return t
+}
+
+// consumeToken consumes the current token of the kind and requires the separator that follows
+// it: a colon after an object key and a comma after a value.
+func (r *Lexer) consumeToken(kind Kind) {
+	r.consume()
+
+	switch kind {
+	case Object, Array:
+	default:
+		if n := len(r.path); n > 0 && r.path[n-1].object && !r.path[n-1].wantKey {
+			r.WantColon()
+		} else {
+			r.WantComma()
+		}
+	}
+}
+
+// pathFrame is an array or an object enclosing the current token.
+type pathFrame struct {
+	object  bool
+	wantKey bool   // Whether the next token of the object is a key.
+	hasKey  bool   // Whether a key of the object was read.
+	key     []byte // Last key of the object.
+	members int    // Number of keys of the object read so far.
+	index   int    // Index of the current array element, -1 before the first one.
+}
+
+// trackPath updates the path with the current token.
+func (r *Lexer) trackPath() {
+	// A token can be fetched more than once, e.g. when it is skipped after an error.
+	pos := r.start + r.offset + 1
+	if pos == r.pathPos {
+		return
+	}
+	r.pathPos = pos
+
+	if r.token.kind == tokenDelim && (r.token.delimValue == '}' || r.token.delimValue == ']') {
+		r.popPath()
+		return
+	}
+
+	if n := len(r.path); n > 0 {
+		f := &r.path[n-1]
+		if f.object {
+			if f.wantKey {
+				f.key = append(f.key[:0], r.token.byteValue...)
+				f.hasKey = true
+				f.wantKey = false
+				f.members++
+				if r.MaxMembers > 0 && f.members > r.MaxMembers {
+					r.errLimit(ReasonMaxMembers)
+				}
+				return
+			}
+			f.wantKey = true
+		} else {
+			f.index++
+			if r.MaxElements > 0 && f.index >= r.MaxElements {
+				r.errLimit(ReasonMaxElements)
+				return
+			}
+		}
+	}
+
+	if r.token.kind == tokenDelim {
+		if r.MaxDepth > 0 && len(r.path) >= r.MaxDepth {
+			r.errLimit(ReasonMaxDepth)
+			return
+		}
+		r.pushPath(r.token.delimValue == '{')
+	}
+}
+
+// Path tracking.
+//
+// The path costs time on every token, but only the token API, input limits and errors need
+// it. So, unless the lexer streams its input or has limits, the path is not tracked while
+// decoding: frames() computes it when it is needed by scanning the input up to the last
+// fetched token with a second lexer, which continues from where it stopped the last time.
+// NextToken starts tracking it token by token, since it needs the path for every token.
+
+// startTracking starts tracking the path token by token from the last fetched token on.
+func (r *Lexer) startTracking() {
+	if r.Reader == nil {
+		s := r.scanPath()
+		r.path = append(r.path[:0], s.path...)
+		r.pathPos = s.pathPos
+		r.scan = nil
+	} else if r.token.kind != tokenUndef {
+		// The stream is tracked from its first token on.
+		r.trackPath()
+	}
+	r.tracking = true
+}
+
+// frames returns the enclosing arrays and objects of the last fetched token.
+func (r *Lexer) frames() []pathFrame {
+	if r.tracking || r.Reader != nil {
+		return r.path
+	}
+	return r.scanPath().path
+}
+
+// scanPath advances the scanning lexer over the tokens up to the last fetched token.
+func (r *Lexer) scanPath() *Lexer {
+	target := r.start
+	if r.pos == 0 {
+		target = -1 // No token was fetched yet.
+	}
+	s := r.scan
+	if s == nil || s.pathPos > target+1 || len(s.Data) != len(r.Data) {
+		s = &Lexer{Data: r.Data, tracking: true}
+		r.scan = s
+	}
+	for {
+		if s.token.kind == tokenUndef && s.Ok() {
+			s.fetchToken()
+		}
+		if !s.Ok() || s.token.kind == tokenUndef || s.start > target {
+			return s
+		}
+		s.trackPath()
+		s.consumeToken(s.kind())
+	}
+}
+
+// pushPath adds a frame for a new array or object to the path, reusing the memory of the
+// previously used frames.
+func (r *Lexer) pushPath(object bool) {
+	n := len(r.path)
+	if n < cap(r.path) {
+		r.path = r.path[:n+1]
+	} else {
+		r.path = append(r.path, pathFrame{})
+	}
+	f := &r.path[n]
+	f.object = object
+	f.wantKey = object
+	f.hasKey = false
+	f.key = f.key[:0]
+	f.members = 0
+	f.index = -1
+}
+
+// popPath removes the innermost frame from the path.
+func (r *Lexer) popPath() {
+	if n := len(r.path); n > 0 {
+		r.path = r.path[:n-1]
+	}
+}
+
+// Path returns the JSON pointer (RFC 6901) of the value the last fetched token belongs to: an
+// object key belongs to the value it names, and a closing delimiter to the array or object it
+// ends. The root value has an empty path.
+func (r *Lexer) Path() string {
+	var b strings.Builder
+	for _, f := range r.frames() {
+		if f.object {
+			if !f.hasKey {
+				break
+			}
+			b.WriteByte('/')
+			escapePointerToken(&b, f.key)
+		} else {
+			if f.index < 0 {
+				break
+			}
+			b.WriteByte('/')
+			b.WriteString(strconv.Itoa(f.index))
+		}
+	}
+	return b.String()
+}
+
+// escapePointerToken writes a JSON pointer reference token, escaping '~' and '/'.
+func escapePointerToken(b *strings.Builder, key []byte) {
+	for _, c := range key {
+		switch c {
+		case '~':
+			b.WriteString("~0")
+		case '/':
+			b.WriteString("~1")
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package jlexer
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNextToken(t *testing.T) {
+	type tok struct {
+		Kind  Kind
+		Value string
+		Path  string
+	}
+
+	for i, test := range []struct {
+		toParse string
+		want    []tok
+	}{
+		{
+			toParse: `"str"`,
+			want:    []tok{{String, "str", ""}},
+		},
+		{
+			toParse: `{"a": 1, "b": [true, null, {"c~/": "x"}], "d": {}}`,
+			want: []tok{
+				{Object, "", ""},
+				{String, "a", "/a"},
+				{Number, "1", "/a"},
+				{String, "b", "/b"},
+				{Array, "", "/b"},
+				{Bool, "", "/b/0"},
+				{Null, "", "/b/1"},
+				{Object, "", "/b/2"},
+				{String, "c~/", "/b/2/c~0~1"},
+				{String, "x", "/b/2/c~0~1"},
+				{ObjectEnd, "", "/b/2"},
+				{ArrayEnd, "", "/b"},
+				{String, "d", "/d"},
+				{Object, "", "/d"},
+				{ObjectEnd, "", "/d"},
+				{ObjectEnd, "", ""},
+			},
+		},
+	} {
+		l := Lexer{Data: []byte(test.toParse)}
+
+		var got []tok
+		for {
+			token := l.NextToken()
+			if token.Kind == Invalid {
+				break
+			}
+			got = append(got, tok{token.Kind, string(token.Value), l.Path()})
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("[%d, %q] NextToken() = %v; want %v", i, test.toParse, got, test.want)
+		}
+	}
+}
+
+func TestPeekKind(t *testing.T) {
+	for i, test := range []struct {
+		toParse string
+		want    Kind
+	}{
+		{toParse: `{}`, want: Object},
+		{toParse: `[]`, want: Array},
+		{toParse: `"a"`, want: String},
+		{toParse: `-1.5`, want: Number},
+		{toParse: `false`, want: Bool},
+		{toParse: `null`, want: Null},
+		{toParse: ``, want: Invalid},
+		{toParse: `x`, want: Invalid},
+	} {
+		l := Lexer{Data: []byte(test.toParse)}
+
+		got := l.PeekKind()
+		if got != test.want {
+			t.Errorf("[%d, %q] PeekKind() = %v; want %v", i, test.toParse, got, test.want)
+		}
+		if got2 := l.PeekKind(); got2 != got {
+			t.Errorf("[%d, %q] PeekKind() consumed the token", i, test.toParse)
+		}
+	}
+}
+
+func TestPathSkip(t *testing.T) {
+	l := Lexer{Data: []byte(`{"a": {"x": [1, 2]}, "b": [3, {"y": 4}]}`)}
+
+	l.Delim('{')
+	l.UnsafeString()
+	l.WantColon()
+	l.SkipRecursive()
+	l.WantComma()
+	l.UnsafeString()
+	l.WantColon()
+	l.Delim('[')
+	l.Int()
+	l.WantComma()
+	if got := l.PeekKind(); got != Object {
+		t.Errorf("PeekKind() = %v; want %v", got, Object)
+	}
+	if got := l.Path(); got != "/b/1" {
+		t.Errorf("Path() = %q; want %q", got, "/b/1")
+	}
+}
+
+func TestPathMultipleErrors(t *testing.T) {
+	l := Lexer{Data: []byte(`[{"a": 1}, [2]]`), UseMultipleErrors: true}
+
+	l.Delim('[')
+	_ = l.String()
+	l.WantComma()
+	l.Int()
+	if got := l.Path(); got != "/1" {
+		t.Errorf("Path() = %q; want %q", got, "/1")
+	}
+	if len(l.GetNonFatalErrors()) != 2 {
+		t.Errorf("GetNonFatalErrors() = %v; want 2 errors", l.GetNonFatalErrors())
+	}
+}
+
+// walkPaths decodes data with the methods generated decoders use, recording the path after
+// each of them.
+func walkPaths(l *Lexer) []string {
+	var paths []string
+	step := func() { paths = append(paths, l.Path()) }
+
+	l.Delim('{')
+	for !l.IsDelim('}') {
+		key := l.UnsafeString()
+		step()
+		l.WantColon()
+		if key == "skip" {
+			l.SkipRecursive()
+			step()
+		} else {
+			l.Delim('[')
+			for !l.IsDelim(']') {
+				l.Int()
+				step()
+				l.WantComma()
+			}
+			l.Delim(']')
+			step()
+		}
+		l.WantComma()
+	}
+	l.Delim('}')
+	step()
+	return paths
+}
+
+func TestPathUntracked(t *testing.T) {
+	data := []byte(`{"a": [1, 2], "skip": {"x": [1, {"y": 2}]}, "b/c": [3]}`)
+
+	untracked := Lexer{Data: data}
+	got := walkPaths(&untracked)
+	if untracked.tracking {
+		t.Errorf("path tracked while decoding")
+	}
+
+	// Limits make the lexer track the path token by token.
+	tracked := Lexer{Data: data, MaxDepth: 10}
+	want := walkPaths(&tracked)
+	if !tracked.tracking {
+		t.Errorf("path not tracked with limits")
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Path() = %q; want %q", got, want)
+	}
+}
+
+func benchDocument() []byte {
+	items := make([]string, 100)
+	for i := range items {
+		items[i] = `{"id":` + strconv.Itoa(i) + `,"name":"item ` + strconv.Itoa(i) + `","tags":["a","b","c"],"price":12.5,"meta":{"color":"red","size":3}}`
+	}
+	return []byte(`{"count":100,"items":[` + strings.Join(items, ",") + `]}`)
+}
+
+func decodeBench(in *Lexer) {
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		switch key {
+		case "count":
+			in.Int()
+		case "items":
+			in.Delim('[')
+			for !in.IsDelim(']') {
+				in.Delim('{')
+				for !in.IsDelim('}') {
+					key := in.UnsafeString()
+					in.WantColon()
+					switch key {
+					case "id":
+						in.Int()
+					case "name":
+						_ = in.String()
+					case "tags":
+						in.Delim('[')
+						for !in.IsDelim(']') {
+							_ = in.String()
+							in.WantComma()
+						}
+						in.Delim(']')
+					case "price":
+						in.Float64()
+					default:
+						in.SkipRecursive()
+					}
+					in.WantComma()
+				}
+				in.Delim('}')
+				in.WantComma()
+			}
+			in.Delim(']')
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+}
+
+func BenchmarkDecode(b *testing.B) {
+	data := benchDocument()
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		in := Lexer{Data: data}
+		decodeBench(&in)
+		if err := in.Error(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package jlexer
+
+// Discriminator returns the value of the string member key of the object starting at the
+// current token, without consuming the object. It is used to select the type of a variant
+// before decoding it, wherever the member appears in the object. An empty string is returned
+// if the current token does not start an object or the object has no such member.
+//
+// In streaming mode the whole object is read into the window first, checking the nesting and
+// string limits as it is read. As with UnsafeString, the result is only valid until the next
+// token is fetched.
+func (r *Lexer) Discriminator(key string) string {
+	if r.token.kind == tokenUndef && r.Ok() {
+		r.FetchToken()
+	}
+	if !r.Ok() || r.token.kind != tokenDelim || r.token.delimValue != '{' {
+		return ""
+	}
+	if r.Reader != nil {
+		// The path includes the object, which the scanner counts as well.
+		s := valueScanner{outerDepth: len(r.path) - 1}
+		for {
+			end, reason := s.scan(r, r.Data[r.start:])
+			if reason != "" {
+				r.errLimit(reason)
+				return ""
+			}
+			if end >= 0 || r.eof || !r.Ok() {
+				break
+			}
+			r.fill()
+		}
+	}
+
+	sub := r.SubLexer(r.Data[r.start:])
+	sub.Delim('{')
+	for sub.Ok() && !sub.IsDelim('}') {
+		k := sub.UnsafeString()
+		sub.WantColon()
+		if k == key && sub.PeekKind() == String {
+			return sub.UnsafeString()
+		}
+		sub.SkipRecursive()
+		sub.WantComma()
+	}
+	if err := sub.Error(); err != nil && r.Ok() {
+		// Errors other than limits are reported when the object is decoded.
+		if lerr, ok := err.(*LexerError); ok && isLimitReason(lerr.Reason) {
+			r.errLimit(lerr.Reason)
+		}
+	}
+	return ""
+}
+
+// isLimitReason returns whether the reason is one of an input limit.
+func isLimitReason(reason string) bool {
+	switch reason {
+	case ReasonMaxDepth, ReasonMaxStringLen, ReasonMaxMembers, ReasonMaxElements, ReasonMaxInputSize:
+		return true
+	}
+	return false
+}
+
+// valueScanner finds the end of an array or object in data that grows as the window of a
+// stream is filled, continuing where it stopped, so that the data is scanned once.
+type valueScanner struct {
+	outerDepth int // Depth of the arrays and objects enclosing the value.
+
+	pos         int
+	depth       int
+	inString    bool
+	escaped     bool
+	stringStart int
+}
+
+// scan returns the length of the value data starts with, or -1 if data does not contain all of
+// it yet. The reason of an error is returned if the value exceeds the nesting or string limits
+// of the lexer.
+func (s *valueScanner) scan(r *Lexer, data []byte) (end int, reason string) {
+	for ; s.pos < len(data); s.pos++ {
+		c := data[s.pos]
+		switch {
+		case s.escaped:
+			s.escaped = false
+		case s.inString:
+			if c == '\\' {
+				s.escaped = true
+			} else if c == '"' {
+				s.inString = false
+			}
+		case c == '"':
+			s.inString = true
+			s.stringStart = s.pos
+		case c == '{' || c == '[':
+			s.depth++
+			if r.MaxDepth > 0 && s.outerDepth+s.depth > r.MaxDepth {
+				return -1, ReasonMaxDepth
+			}
+		case c == '}' || c == ']':
+			s.depth--
+			if s.depth == 0 {
+				return s.pos + 1, ""
+			}
+		}
+	}
+	if s.inString && r.MaxStringLen > 0 && s.pos-s.stringStart-1 > r.MaxStringLen {
+		return -1, ReasonMaxStringLen
+	}
+	return -1, ""
+}
@@ -0,0 +1,78 @@
+package jlexer
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDiscriminator(t *testing.T) {
+	for i, test := range []struct {
+		toParse string
+		want    string
+	}{
+		{toParse: `{"type":"circle","r":1}`, want: "circle"},
+		{toParse: `{"r":{"type":"inner"},"s":"}","type":"circle"}`, want: "circle"},
+		{toParse: `{"r":1}`, want: ""},
+		{toParse: `{"type":1}`, want: ""},
+		{toParse: `["type","circle"]`, want: ""},
+		{toParse: `null`, want: ""},
+	} {
+		for _, stream := range []bool{false, true} {
+			l := Lexer{Data: []byte(test.toParse)}
+			if stream {
+				l = Lexer{Reader: iotest.OneByteReader(strings.NewReader(test.toParse))}
+			}
+			if got := l.Discriminator("type"); got != test.want {
+				t.Errorf("[%d, %q, stream %v] Discriminator() = %q; want %q", i, test.toParse, stream, got, test.want)
+			}
+			// The value is not consumed.
+			l.SkipRecursive()
+			l.Consumed()
+			if err := l.Error(); err != nil {
+				t.Errorf("[%d, %q, stream %v] error: %v", i, test.toParse, stream, err)
+			}
+		}
+	}
+}
+
+func TestDiscriminatorLimits(t *testing.T) {
+	for i, test := range []struct {
+		toParse string
+		lexer   Lexer
+		reason  string
+	}{
+		{toParse: `{"a":"` + strings.Repeat("x", 100) + `","type":"circle"}`, lexer: Lexer{MaxStringLen: 10}, reason: ReasonMaxStringLen},
+		{toParse: `{"a":[[[[1]]]],"type":"circle"}`, lexer: Lexer{MaxDepth: 3}, reason: ReasonMaxDepth},
+		{toParse: `{"a":[1,2,3,4],"type":"circle"}`, lexer: Lexer{MaxElements: 3}, reason: ReasonMaxElements},
+		{toParse: `{"a":1,"b":2,"type":"circle"}`, lexer: Lexer{MaxMembers: 2}, reason: ReasonMaxMembers},
+	} {
+		for _, stream := range []bool{false, true} {
+			l := test.lexer
+			if stream {
+				l.Reader = iotest.OneByteReader(strings.NewReader(test.toParse))
+			} else {
+				l.Data = []byte(test.toParse)
+			}
+			if got := l.Discriminator("type"); got != "" {
+				t.Errorf("[%d, stream %v] Discriminator() = %q; want none", i, stream, got)
+			}
+			if err, ok := l.Error().(*LexerError); !ok || err.Reason != test.reason {
+				t.Errorf("[%d, stream %v] error: %v; want %s", i, stream, l.Error(), test.reason)
+			}
+		}
+	}
+}
+
+func TestDiscriminatorStreamWindow(t *testing.T) {
+	// The string exceeds the limit long before the object is read into the window.
+	r := strings.NewReader(`{"a":"` + strings.Repeat("x", 1<<20) + `","type":"circle"}`)
+	l := Lexer{Reader: r, MaxStringLen: 1000}
+	l.Discriminator("type")
+	if l.Ok() {
+		t.Fatalf("Discriminator() ok; want error")
+	}
+	if len(l.Data) >= 1<<20 {
+		t.Errorf("window of %d bytes; want the object not to be read", len(l.Data))
+	}
+}
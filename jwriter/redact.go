@@ -0,0 +1,67 @@
+package jwriter
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// Redaction selects how generated encoders write fields tagged with partial:"sensitive", so
+// that values such as tokens and emails can be logged with the same marshalers.
+type Redaction int
+
+const (
+	RedactNone        Redaction = iota // Sensitive fields are written as they are.
+	RedactPlaceholder                  // Values of sensitive fields are written as the placeholder.
+	RedactOmit                         // Sensitive fields are omitted.
+	RedactHash                         // Values of sensitive fields are written as keyed hashes.
+)
+
+// DefaultPlaceholder is written for redacted values if the writer has no Placeholder.
+const DefaultPlaceholder = "***"
+
+// ErrNoHashKey is the error of writing a redacted value with RedactHash and no HashKey, whose
+// hashes could be reversed by hashing guesses of the values.
+var ErrNoHashKey = errors.New("jwriter: RedactHash requires a HashKey")
+
+// OmitsSensitive returns whether fields tagged as sensitive are omitted.
+func (w *Writer) OmitsSensitive() bool {
+	return w.RedactSensitive == RedactOmit
+}
+
+// Redacted writes a sensitive value, which encode writes, as selected by RedactSensitive: as
+// the placeholder, or as the hex encoded HMAC-SHA256 of its JSON encoding keyed with HashKey,
+// so that equal values are written the same way. Hashing without a HashKey sets Error to
+// ErrNoHashKey. It must not be called with RedactNone.
+func (w *Writer) Redacted(encode func(w *Writer)) {
+	if w.RedactSensitive != RedactHash {
+		if w.Placeholder == "" {
+			w.String(DefaultPlaceholder)
+		} else {
+			w.String(w.Placeholder)
+		}
+		return
+	}
+	if len(w.HashKey) == 0 {
+		w.Error = ErrNoHashKey
+		return
+	}
+
+	v := Writer{
+		Flags:           w.Flags,
+		NoEscapeHTML:    w.NoEscapeHTML,
+		View:            w.View,
+		RedactSensitive: w.RedactSensitive,
+		HashKey:         w.HashKey,
+	}
+	encode(&v)
+	data, err := v.BuildBytes()
+	if err != nil {
+		w.Error = err
+		return
+	}
+	mac := hmac.New(sha256.New, w.HashKey)
+	mac.Write(data)
+	w.String(hex.EncodeToString(mac.Sum(nil)))
+}
@@ -0,0 +1,26 @@
+package jwriter
+
+import "testing"
+
+func TestRedacted(t *testing.T) {
+	encode := func(w *Writer) { w.String("secret") }
+	for i, test := range []struct {
+		Writer Writer
+		Want   string
+		Error  error
+	}{
+		{Writer: Writer{RedactSensitive: RedactPlaceholder}, Want: `"***"`},
+		{Writer: Writer{RedactSensitive: RedactPlaceholder, Placeholder: "-"}, Want: `"-"`},
+		{Writer: Writer{RedactSensitive: RedactHash, HashKey: []byte("key")}, Want: `"4cb94b39b8c15ad9abf0c56b1cbab3fbcc924b04d3638f616ddbf1995b6428f6"`},
+		{Writer: Writer{RedactSensitive: RedactHash}, Error: ErrNoHashKey},
+	} {
+		w := test.Writer
+		w.Redacted(encode)
+		got, err := w.BuildBytes()
+		if err != test.Error {
+			t.Errorf("[%d] error = %v; want %v", i, err, test.Error)
+		} else if err == nil && string(got) != test.Want {
+			t.Errorf("[%d] Redacted() = %s; want %s", i, got, test.Want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package jwriter
+
+import (
+	"time"
+)
+
+// Time writes t in RFC 3339 format, as time.Time.MarshalJSON does.
+func (w *Writer) Time(t time.Time) {
+	w.TimeLayout(t, time.RFC3339Nano)
+}
+
+// TimeLayout writes t as a string formatted with the layout, see time.Time.Format. The layout
+// is written as is, so it must not contain characters that need escaping.
+func (w *Writer) TimeLayout(t time.Time, layout string) {
+	w.Buffer.EnsureSpace(len(layout) + 16)
+	w.Buffer.Buf = append(w.Buffer.Buf, '"')
+	w.Buffer.Buf = t.AppendFormat(w.Buffer.Buf, layout)
+	w.Buffer.Buf = append(w.Buffer.Buf, '"')
+}
+
+// UnixTime writes t as a number of seconds since the Unix epoch.
+func (w *Writer) UnixTime(t time.Time) {
+	w.Int64(t.Unix())
+}
+
+// UnixMilliTime writes t as a number of milliseconds since the Unix epoch.
+func (w *Writer) UnixMilliTime(t time.Time) {
+	w.Int64(t.Unix()*1e3 + int64(t.Nanosecond())/1e6)
+}
+
+// Duration writes d as a string such as "1h30m0s", see time.Duration.String.
+func (w *Writer) Duration(d time.Duration) {
+	w.String(d.String())
+}
+
+// DurationMilli writes d as a number of milliseconds.
+func (w *Writer) DurationMilli(d time.Duration) {
+	w.Int64(int64(d / time.Millisecond))
+}
@@ -2,7 +2,10 @@
 package jwriter
 
 import (
+	"encoding/json"
+	"errors"
 	"io"
+	"math/big"
 	"strconv"
 	"unicode/utf8"
 
@@ -25,6 +28,71 @@ type Writer struct {
 	Error        error
 	Buffer       buffer.Buffer
 	NoEscapeHTML bool
+
+	// View selects the fields written by generated encoders: fields tagged with views are
+	// skipped unless View is one of them. All fields are written if View is empty.
+	View string
+
+	// RedactSensitive selects how generated encoders write fields tagged as sensitive, see
+	// redact.go. Placeholder and HashKey configure the redacted values.
+	RedactSensitive Redaction
+	Placeholder     string // Written for redacted values, DefaultPlaceholder if empty.
+	HashKey         []byte // Key of the hashes of redacted values.
+}
+
+// InView returns whether a field tagged with the views is written in the view of the writer.
+func (w *Writer) InView(views ...string) bool {
+	if w.View == "" {
+		return true
+	}
+	for _, v := range views {
+		if v == w.View {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultStreamThreshold is the amount of buffered data after which a stream writer writes
+// out completed chunks.
+const DefaultStreamThreshold = 32 * 1024
+
+// NewStreamWriter creates a writer that writes the data to out as it is produced, once at
+// least threshold bytes are buffered. Flush must be called after the data is written to
+// send out the rest of it. If threshold is not positive, DefaultStreamThreshold is used.
+// A write error is recorded in Error as soon as it occurs, so that encoders can stop early.
+func NewStreamWriter(out io.Writer, threshold int) *Writer {
+	if threshold <= 0 {
+		threshold = DefaultStreamThreshold
+	}
+	w := &Writer{}
+	w.Buffer.StreamTo(out, threshold)
+	w.Buffer.OnError = w.streamError
+	return w
+}
+
+// streamError records the first write error of a stream writer.
+func (w *Writer) streamError(err error) {
+	if w.Error == nil {
+		w.Error = err
+	}
+}
+
+// Flush writes all buffered data of a stream writer out, recording a write error in Error.
+// Nothing is written if an error was encountered before.
+func (w *Writer) Flush() error {
+	if w.Error != nil {
+		return w.Error
+	}
+	if _, err := w.Buffer.Flush(); err != nil {
+		w.streamError(err)
+	}
+	return w.Error
+}
+
+// Written returns the number of bytes a stream writer has written out.
+func (w *Writer) Written() int {
+	return w.Buffer.Written()
 }
 
 // Size returns the size of the data that was written out.
@@ -32,27 +100,35 @@ func (w *Writer) Size() int {
 	return w.Buffer.Size()
 }
 
-// DumpTo outputs the data to given io.Writer, resetting the buffer.
+// DumpTo outputs the data to given io.Writer, resetting the buffer. It fails for a stream
+// writer, whose data is written out with Flush.
 func (w *Writer) DumpTo(out io.Writer) (written int, err error) {
 	return w.Buffer.DumpTo(out)
 }
 
 // BuildBytes returns writer data as a single byte slice. You can optionally provide one byte slice
-// as argument that it will try to reuse.
+// as argument that it will try to reuse. It fails for a stream writer, whose data is written
+// out with Flush.
 func (w *Writer) BuildBytes(reuse ...[]byte) ([]byte, error) {
 	if w.Error != nil {
 		return nil, w.Error
 	}
+	if w.Buffer.Streamed() {
+		return nil, buffer.ErrStreamed
+	}
 
 	return w.Buffer.BuildBytes(reuse...), nil
 }
 
 // ReadCloser returns an io.ReadCloser that can be used to read the data.
-// ReadCloser also resets the buffer.
+// ReadCloser also resets the buffer. It fails for a stream writer.
 func (w *Writer) ReadCloser() (io.ReadCloser, error) {
 	if w.Error != nil {
 		return nil, w.Error
 	}
+	if w.Buffer.Streamed() {
+		return nil, buffer.ErrStreamed
+	}
 
 	return w.Buffer.ReadCloser(), nil
 }
@@ -259,6 +335,89 @@ func (w *Writer) Float64Str(n float64) {
 	w.Buffer.Buf = append(w.Buffer.Buf, '"')
 }
 
+// BigInt writes an integer of arbitrary size; nil is written as null.
+func (w *Writer) BigInt(n *big.Int) {
+	if n == nil {
+		w.RawString("null")
+		return
+	}
+	w.Buffer.AppendString(n.String())
+}
+
+// BigFloat writes a float of arbitrary precision with the shortest representation that
+// round-trips; nil is written as null. Infinities cannot be represented and set Error.
+func (w *Writer) BigFloat(n *big.Float) {
+	switch {
+	case n == nil:
+		w.RawString("null")
+	case n.IsInf():
+		if w.Error == nil {
+			w.Error = errors.New("jwriter: unsupported value: " + n.String())
+		}
+	default:
+		w.Buffer.AppendString(n.Text('g', -1))
+	}
+}
+
+// JsonNumber writes the literal of a json.Number as is; an empty number is written as 0. A
+// literal that is not a valid JSON number sets Error, as it would be written into the output.
+func (w *Writer) JsonNumber(n json.Number) {
+	if n == "" {
+		w.RawByte('0')
+		return
+	}
+	if !isValidNumber(string(n)) {
+		if w.Error == nil {
+			w.Error = errors.New("jwriter: invalid number literal " + strconv.Quote(string(n)))
+		}
+		return
+	}
+	w.Buffer.AppendString(string(n))
+}
+
+// isValidNumber checks that s is a number literal of the JSON grammar.
+func isValidNumber(s string) bool {
+	if s != "" && s[0] == '-' {
+		s = s[1:]
+	}
+	switch {
+	case s == "":
+		return false
+	case s[0] == '0':
+		s = s[1:]
+	case s[0] >= '1' && s[0] <= '9':
+		s = skipDigits(s[1:])
+	default:
+		return false
+	}
+
+	if len(s) >= 2 && s[0] == '.' && isDigit(s[1]) {
+		s = skipDigits(s[2:])
+	}
+	if len(s) >= 2 && (s[0] == 'e' || s[0] == 'E') {
+		s = s[1:]
+		if s[0] == '+' || s[0] == '-' {
+			s = s[1:]
+		}
+		if s == "" || !isDigit(s[0]) {
+			return false
+		}
+		s = skipDigits(s)
+	}
+	return s == ""
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func skipDigits(s string) string {
+	for s != "" && isDigit(s[0]) {
+		s = s[1:]
+	}
+	return s
+}
+
 func (w *Writer) Bool(v bool) {
 	w.Buffer.EnsureSpace(5)
 	if v {
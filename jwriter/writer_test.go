@@ -0,0 +1,129 @@
+package jwriter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/reddyvinod/partialencode/buffer"
+)
+
+func TestStreamWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := NewStreamWriter(&out, 1024)
+
+	w.RawByte('[')
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			w.RawByte(',')
+		}
+		w.String("test")
+	}
+	w.RawByte(']')
+	want := "[" + strings.Repeat(`"test",`, 999) + `"test"]`
+
+	if out.Len() == 0 {
+		t.Errorf("nothing written before Flush()")
+	}
+	if err := w.Flush(); err != nil {
+		t.Errorf("Flush() error: %v", err)
+	}
+	if out.String() != want {
+		t.Errorf("Flush() wrote %q; want %q", out.String(), want)
+	}
+	if w.Written() != len(want) {
+		t.Errorf("Written() = %v; want %v", w.Written(), len(want))
+	}
+}
+
+func TestStreamWriterHoldsNoData(t *testing.T) {
+	var out bytes.Buffer
+	w := NewStreamWriter(&out, 0)
+	w.String("test")
+
+	if _, err := w.BuildBytes(); err != buffer.ErrStreamed {
+		t.Errorf("BuildBytes() error: %v; want %v", err, buffer.ErrStreamed)
+	}
+	if _, err := w.DumpTo(&out); err != buffer.ErrStreamed {
+		t.Errorf("DumpTo() error: %v; want %v", err, buffer.ErrStreamed)
+	}
+	if _, err := w.ReadCloser(); err != buffer.ErrStreamed {
+		t.Errorf("ReadCloser() error: %v; want %v", err, buffer.ErrStreamed)
+	}
+}
+
+type failingWriter struct {
+	n int
+}
+
+var errWrite = errors.New("write failed")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.n < len(p) {
+		n := w.n
+		w.n = 0
+		return n, errWrite
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestStreamWriterError(t *testing.T) {
+	w := NewStreamWriter(&failingWriter{n: 100}, 256)
+
+	// The error is recorded as soon as a chunk fails to be written, before Flush.
+	i := 0
+	for ; w.Error == nil && i < 10000; i++ {
+		w.String("test")
+	}
+	if w.Error != errWrite {
+		t.Fatalf("Error = %v after %d strings; want %v", w.Error, i, errWrite)
+	}
+	if i == 10000 {
+		t.Errorf("Error not set while encoding")
+	}
+
+	if err := w.Flush(); err != errWrite {
+		t.Errorf("Flush() error: %v; want %v", err, errWrite)
+	}
+	if w.Written() != 100 {
+		t.Errorf("Written() = %v; want 100", w.Written())
+	}
+}
+
+func TestJsonNumber(t *testing.T) {
+	for _, test := range []struct {
+		n    json.Number
+		want string
+	}{
+		{n: "", want: "0"},
+		{n: "0", want: "0"},
+		{n: "-12", want: "-12"},
+		{n: "1.5e-10", want: "1.5e-10"},
+		{n: "12345678901234567890.25", want: "12345678901234567890.25"},
+		{n: "1E+2", want: "1E+2"},
+		{n: `1,"admin":true`},
+		{n: "01"},
+		{n: "1."},
+		{n: ".5"},
+		{n: "1e"},
+		{n: "+1"},
+		{n: "NaN"},
+		{n: "-"},
+	} {
+		var w Writer
+		w.JsonNumber(test.n)
+		got, err := w.BuildBytes()
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("JsonNumber(%q) = %s; want error", test.n, got)
+			}
+			continue
+		}
+		if err != nil || string(got) != test.want {
+			t.Errorf("JsonNumber(%q) = %s, %v; want %s", test.n, got, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package partialencode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/reddyvinod/partialencode/jlexer"
+	"github.com/reddyvinod/partialencode/jwriter"
+)
+
+// LineError is an error that occurred while decoding a line of newline-delimited JSON.
+type LineError struct {
+	Line int // 1-based number of the line.
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// LineDecoder decodes newline-delimited JSON (JSON Lines), one value per line. Blank lines
+// are skipped.
+type LineDecoder struct {
+	// SkipInvalid makes Decode skip the lines that fail to decode instead of returning an
+	// error; the errors are collected and available through Errors.
+	SkipInvalid bool
+
+	r      *bufio.Reader
+	buf    []byte
+	line   int
+	errors []*LineError
+}
+
+// NewLineDecoder creates a decoder reading newline-delimited JSON from r.
+func NewLineDecoder(r io.Reader) *LineDecoder {
+	return &LineDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode decodes the next line into v. It returns io.EOF when there are no more lines and
+// a *LineError if the line is not valid. The line buffer is reused, so v should not keep
+// references to the data it was decoded from (see jlexer.Lexer.UnsafeString).
+func (d *LineDecoder) Decode(v Unmarshaler) error {
+	for {
+		line, err := d.readLine()
+		if len(line) == 0 && err != nil {
+			return err
+		}
+		d.line++
+
+		if isBlank(line) {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		l := jlexer.Lexer{Data: line}
+		v.UnMarshalPartialJSON(&l)
+		l.Consumed()
+		if lerr := l.Error(); lerr != nil {
+			lineErr := &LineError{Line: d.line, Err: lerr}
+			if !d.SkipInvalid {
+				return lineErr
+			}
+			d.errors = append(d.errors, lineErr)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		return nil
+	}
+}
+
+// Line returns the number of the last line read.
+func (d *LineDecoder) Line() int {
+	return d.line
+}
+
+// Errors returns the errors of the lines skipped because of SkipInvalid.
+func (d *LineDecoder) Errors() []*LineError {
+	return d.errors
+}
+
+// readLine reads the next line, including the newline character.
+func (d *LineDecoder) readLine() ([]byte, error) {
+	d.buf = d.buf[:0]
+	for {
+		chunk, err := d.r.ReadSlice('\n')
+		d.buf = append(d.buf, chunk...)
+		if err != bufio.ErrBufferFull {
+			return d.buf, err
+		}
+	}
+}
+
+func isBlank(data []byte) bool {
+	for _, c := range data {
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return false
+		}
+	}
+	return true
+}
+
+// LineEncoder encodes values as newline-delimited JSON (JSON Lines), one value per line.
+// A single jwriter.Writer is reused for all the values and its buffers are returned to the
+// pool after every line.
+type LineEncoder struct {
+	out io.Writer
+	jw  jwriter.Writer
+}
+
+// NewLineEncoder creates an encoder writing newline-delimited JSON to w.
+func NewLineEncoder(w io.Writer) *LineEncoder {
+	return &LineEncoder{out: w}
+}
+
+// Encode writes v followed by a newline. Nothing is written if v fails to encode.
+func (e *LineEncoder) Encode(v Marshaler) error {
+	v.MarshalPartialJSON(&e.jw)
+	if err := e.jw.Error; err != nil {
+		e.jw.DumpTo(ioutil.Discard)
+		e.jw.Error = nil
+		return err
+	}
+
+	e.jw.RawByte('\n')
+	_, err := e.jw.DumpTo(e.out)
+	return err
+}
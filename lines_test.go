@@ -0,0 +1,87 @@
+package partialencode
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLineDecoder(t *testing.T) {
+	input := "{\"a\": 1}\n\n  [1, 2]\r\n\"last\""
+	want := []string{`{"a": 1}`, `[1, 2]`, `"last"`}
+
+	d := NewLineDecoder(strings.NewReader(input))
+	var got []string
+	for {
+		var v RawMessage
+		err := d.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode() error: %v", err)
+		}
+		got = append(got, string(v))
+	}
+
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Decode() = %q; want %q", got, want)
+	}
+	if d.Line() != 4 {
+		t.Errorf("Line() = %d; want 4", d.Line())
+	}
+}
+
+func TestLineDecoderErrors(t *testing.T) {
+	input := "1\n{\"a\": \n3\n4 5\n"
+
+	d := NewLineDecoder(strings.NewReader(input))
+	var v RawMessage
+	if err := d.Decode(&v); err != nil {
+		t.Fatalf("Decode() error: %v", err)
+	}
+	err := d.Decode(&v)
+	if lerr, ok := err.(*LineError); !ok || lerr.Line != 2 {
+		t.Errorf("Decode() error = %v; want *LineError at line 2", err)
+	}
+
+	d = NewLineDecoder(strings.NewReader(input))
+	d.SkipInvalid = true
+	var got []string
+	for {
+		var v RawMessage
+		err := d.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode() error: %v", err)
+		}
+		got = append(got, string(v))
+	}
+
+	if strings.Join(got, "|") != "1|3" {
+		t.Errorf("Decode() = %q; want [1 3]", got)
+	}
+	errs := d.Errors()
+	if len(errs) != 2 || errs[0].Line != 2 || errs[1].Line != 4 {
+		t.Errorf("Errors() = %v; want errors at lines 2 and 4", errs)
+	}
+}
+
+func TestLineEncoder(t *testing.T) {
+	var out bytes.Buffer
+
+	e := NewLineEncoder(&out)
+	for _, v := range []RawMessage{RawMessage(`{"a":1}`), RawMessage(`[1,2]`), nil} {
+		if err := e.Encode(&v); err != nil {
+			t.Fatalf("Encode() error: %v", err)
+		}
+	}
+
+	want := "{\"a\":1}\n[1,2]\nnull\n"
+	if out.String() != want {
+		t.Errorf("Encode() = %q; want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,25 @@
+package partialencode
+
+// Conflict is a field that two partials merged with a common base change to different values.
+type Conflict struct {
+	Path   string      // JSON path of the field, e.g. $.address.city.
+	Ours   interface{} // Value of the field in the first partial, nil if it is set to null.
+	Theirs interface{} // Value of the field in the second partial, nil if it is set to null.
+}
+
+// Resolution selects the partial whose value settles a conflict.
+type Resolution int
+
+const (
+	Ours Resolution = iota
+	Theirs
+)
+
+// Resolver settles a conflict of a merge.
+type Resolver func(c Conflict) Resolution
+
+// ResolveOurs settles conflicts with the values of the first partial.
+func ResolveOurs(Conflict) Resolution { return Ours }
+
+// ResolveTheirs settles conflicts with the values of the second partial.
+func ResolveTheirs(Conflict) Resolution { return Theirs }
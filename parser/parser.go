@@ -12,12 +12,51 @@ import (
 )
 
 const structComment = "partialencode:json"
+const enumComment = "partialencode:enum"
+const variantComment = "partialencode:variant"
+const defaultsComment = "partialencode:defaults"
 
 type Parser struct {
 	PkgPath     string
 	PkgName     string
 	StructNames []string
 	AllStructs  bool
+
+	// Enums are the types marked with the enum comment, with the constants declared for them.
+	Enums []Enum
+
+	// Variants are the types marked with the variant comment.
+	Variants []Variant
+
+	// DefaultsHooks are the methods marked with the defaults comment.
+	DefaultsHooks []Hook
+
+	consts map[string][]string
+}
+
+// Enum is a named string or integer type marked with the "partialencode:enum" comment. The
+// comment may name a constant unknown values are decoded to: "partialencode:enum fallback=X".
+type Enum struct {
+	Name     string
+	Values   []string // Names of the constants of the type, in declaration order.
+	Fallback string
+}
+
+// Variant is a type marked with the "partialencode:variant Iface key=value" comment: an
+// implementation of the interface Iface that is encoded with the discriminator member
+// "key": "value".
+type Variant struct {
+	Name      string
+	Interface string
+	Key       string
+	Value     string
+}
+
+// Hook is a method of a type marked with a comment such as "partialencode:defaults", which
+// generated code calls.
+type Hook struct {
+	Type   string
+	Method string
 }
 
 type visitor struct {
@@ -25,10 +64,28 @@ type visitor struct {
 
 	name     string
 	explicit bool
+	enum     *Enum
+	variant  *Variant
+}
+
+// commentLines returns the lines of the comments without their markers and surrounding spaces.
+// Unlike CommentGroup.Text, directives such as "//partialencode:json" are kept, so that both
+// "//partialencode:json" and "// partialencode:json" mark types.
+func commentLines(cg *ast.CommentGroup) []string {
+	if cg == nil {
+		return nil
+	}
+	var ret []string
+	for _, c := range cg.List {
+		for _, line := range strings.Split(strings.TrimPrefix(c.Text, "//"), "\n") {
+			ret = append(ret, strings.TrimSpace(line))
+		}
+	}
+	return ret
 }
 
-func (p *Parser) needType(comments string) bool {
-	for _, v := range strings.Split(comments, "\n") {
+func (p *Parser) needType(comments []string) bool {
+	for _, v := range comments {
 		if strings.HasPrefix(v, structComment) {
 			return true
 		}
@@ -36,6 +93,93 @@ func (p *Parser) needType(comments string) bool {
 	return false
 }
 
+// enumOptions returns the options of the enum comment and whether it is present.
+func (p *Parser) enumOptions(comments []string) (e Enum, ok bool) {
+	for _, v := range comments {
+		if !strings.HasPrefix(v, enumComment) {
+			continue
+		}
+		for _, opt := range strings.Fields(strings.TrimPrefix(v, enumComment)) {
+			if strings.HasPrefix(opt, "fallback=") {
+				e.Fallback = strings.TrimPrefix(opt, "fallback=")
+			}
+		}
+		return e, true
+	}
+	return e, false
+}
+
+// variantOptions returns the options of the variant comment and whether it is present.
+func (p *Parser) variantOptions(comments []string) (vr Variant, ok bool) {
+	for _, v := range comments {
+		if !strings.HasPrefix(v, variantComment) {
+			continue
+		}
+		opts := strings.Fields(strings.TrimPrefix(v, variantComment))
+		if len(opts) != 2 || !strings.Contains(opts[1], "=") {
+			return vr, false
+		}
+		kv := strings.SplitN(opts[1], "=", 2)
+		return Variant{Interface: opts[0], Key: kv[0], Value: kv[1]}, true
+	}
+	return vr, false
+}
+
+// hookOf returns the hook declared by the method n if it is marked with the comment.
+func hookOf(n *ast.FuncDecl, comment string) (h Hook, ok bool) {
+	if n.Recv == nil || len(n.Recv.List) != 1 {
+		return h, false
+	}
+	found := false
+	for _, v := range commentLines(n.Doc) {
+		if strings.TrimSpace(v) == comment {
+			found = true
+		}
+	}
+	if !found {
+		return h, false
+	}
+
+	recv := n.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	ident, ok := recv.(*ast.Ident)
+	if !ok {
+		return h, false
+	}
+	return Hook{Type: ident.Name, Method: n.Name.Name}, true
+}
+
+// addConsts records the names of the constants of a const declaration by their type. As in
+// the language, a spec without a type and values repeats the type of the previous spec.
+func (p *Parser) addConsts(n *ast.GenDecl) {
+	if p.consts == nil {
+		p.consts = map[string][]string{}
+	}
+
+	var typ string
+	for _, spec := range n.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		if ident, ok := vs.Type.(*ast.Ident); ok {
+			typ = ident.Name
+		} else if vs.Type != nil || len(vs.Values) > 0 {
+			typ = ""
+		}
+		if typ == "" {
+			continue
+		}
+		for _, name := range vs.Names {
+			if name.Name != "_" {
+				p.consts[typ] = append(p.consts[typ], name.Name)
+			}
+		}
+	}
+}
+
 func (v *visitor) Visit(n ast.Node) (w ast.Visitor) {
 	switch n := n.(type) {
 	case *ast.Package:
@@ -45,7 +189,22 @@ func (v *visitor) Visit(n ast.Node) (w ast.Visitor) {
 		return v
 
 	case *ast.GenDecl:
-		v.explicit = v.needType(n.Doc.Text())
+		if n.Tok == token.CONST {
+			v.addConsts(n)
+			return nil
+		}
+
+		comments := commentLines(n.Doc)
+		v.enum = nil
+		if e, ok := v.enumOptions(comments); ok {
+			v.enum = &e
+			return v
+		}
+		v.variant = nil
+		if vr, ok := v.variantOptions(comments); ok {
+			v.variant = &vr
+		}
+		v.explicit = v.needType(comments) || v.variant != nil
 
 		if !v.explicit && !v.AllStructs {
 			return nil
@@ -54,15 +213,35 @@ func (v *visitor) Visit(n ast.Node) (w ast.Visitor) {
 	case *ast.TypeSpec:
 		v.name = n.Name.String()
 
-		// Allow to specify non-structs explicitly independent of '-all' flag.
+		if v.enum != nil {
+			e := *v.enum
+			e.Name = v.name
+			v.Enums = append(v.Enums, e)
+			return nil
+		}
+		if v.variant != nil {
+			vr := *v.variant
+			vr.Name = v.name
+			v.Variants = append(v.Variants, vr)
+		}
+
+		// Allow to specify structs explicitly independent of '-all' flag. Only structs have
+		// partial counterparts, so other types marked with the comment are skipped.
 		if v.explicit {
-			v.StructNames = append(v.StructNames, v.name)
+			if _, ok := n.Type.(*ast.StructType); ok {
+				v.StructNames = append(v.StructNames, v.name)
+			}
 			return nil
 		}
 		return v
 	case *ast.StructType:
 		v.StructNames = append(v.StructNames, v.name)
 		return nil
+	case *ast.FuncDecl:
+		if h, ok := hookOf(n, defaultsComment); ok {
+			v.DefaultsHooks = append(v.DefaultsHooks, h)
+		}
+		return nil
 	}
 	return nil
 }
@@ -91,6 +270,11 @@ func (p *Parser) Parse(fname string, isDir bool) error {
 
 		ast.Walk(&visitor{Parser: p}, f)
 	}
+
+	// Constants may be declared before or after their types, or in other files.
+	for i := range p.Enums {
+		p.Enums[i].Values = p.consts[p.Enums[i].Name]
+	}
 	return nil
 }
 
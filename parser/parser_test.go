@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEnums(t *testing.T) {
+	var p Parser
+	if err := p.Parse("testdata/enums.go", false); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Enum{
+		{Name: "Color", Values: []string{"Red", "Green"}},
+		{Name: "Level", Values: []string{"LevelUnknown", "LevelLow", "LevelHigh"}, Fallback: "LevelUnknown"},
+		{Name: "Shade", Values: []string{"ShadeLight", "ShadeDark"}},
+	}
+	if !reflect.DeepEqual(p.Enums, want) {
+		t.Errorf("Enums = %+v; want %+v", p.Enums, want)
+	}
+	if len(p.StructNames) != 0 {
+		t.Errorf("StructNames = %v; want none", p.StructNames)
+	}
+}
+
+func TestParseStructs(t *testing.T) {
+	var p Parser
+	if err := p.Parse("testdata/structs.go", false); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Compact", "Spaced", "Documented"}
+	if !reflect.DeepEqual(p.StructNames, want) {
+		t.Errorf("StructNames = %v; want %v", p.StructNames, want)
+	}
+}
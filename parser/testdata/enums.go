@@ -0,0 +1,35 @@
+package testdata
+
+// Constants may be declared before their type.
+const ShadeLight Shade = 1
+
+//partialencode:enum
+type Color string
+
+const (
+	Red   Color = "red"
+	Green Color = "green"
+	Blue        = Red
+	Other       = "other"
+)
+
+//partialencode:enum fallback=LevelUnknown
+type Level int
+
+const (
+	LevelUnknown Level = iota
+	LevelLow
+	LevelHigh
+	_
+)
+
+// Shade is an enum.
+//
+//partialencode:enum
+type Shade uint8
+
+const ShadeDark Shade = 2
+
+type NotEnum string
+
+const Plain NotEnum = "plain"
@@ -0,0 +1,17 @@
+package testdata
+
+//partialencode:json
+type Compact struct{}
+
+// partialencode:json
+type Spaced struct{}
+
+// Documented is marked after its doc.
+//
+// partialencode:json
+type Documented struct{}
+
+//partialencode:json
+type Slice []int
+
+type Unmarked struct{}
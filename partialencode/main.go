@@ -31,6 +31,16 @@ var processPkg = flag.Bool("pkg", false, "process the whole package instead of j
 var recursive = flag.Bool("recursive", false, "process the directory recursively")
 var excludeDirs = flag.String("exclude_dirs", "", "comma separated list of directories to skip when processing the directory recursively")
 var disallowUnknownFields = flag.Bool("disallow_unknown_fields", false, "return error if any unknown field in json appeared")
+var unset = flag.String("unset", "omit", "how to write fields that are set to null but not valid: omit or null")
+var fullMarshalers = flag.Bool("full", false, "generate MarshalFullJSON methods that write all fields")
+var tracked = flag.Bool("tracked", false, "generate Tracked types that record the fields written through them")
+var merge = flag.Bool("merge", false, "generate MergePartial funcs for three-way merges of partials")
+var compose = flag.Bool("compose", false, "generate ApplyPartial and ComposePartial funcs that apply and coalesce partials")
+var inverse = flag.Bool("inverse", false, "generate InversePartial funcs returning the partials that undo partials")
+var changes = flag.Bool("changes", false, "generate ChangesPartial funcs recording the changes partials make, and ApplyPartial funcs")
+var deepCopy = flag.Bool("deep_copy", false, "generate DeepCopy and Equal methods of structs and their partials")
+var lazyPartials = flag.Bool("lazy_partials", false, "generate LazyPartial types that decode fields on first access")
+var schemaSpecifiedName = flag.String("schema_filename", "", "specify the filename of the JSON Schema output of the schema command")
 
 func generatePartial(fname string) (partialName string, err error) {
 
@@ -69,6 +79,15 @@ func generatePartial(fname string) (partialName string, err error) {
 		PkgPath:               p.PkgPath,
 		PkgName:               p.PkgName,
 		Types:                 p.StructNames,
+		Enums:                 p.Enums,
+		Variants:              p.Variants,
+		DefaultsHooks:         p.DefaultsHooks,
+		Tracked:               *tracked,
+		Merge:                 *merge,
+		Compose:               *compose,
+		Inverse:               *inverse,
+		Changes:               *changes,
+		DeepCopy:              *deepCopy,
 		SnakeCase:             *snakeCase,
 		LowerCamelCase:        *lowerCamelCase,
 		NoStdMarshalers:       *noStdMarshalers,
@@ -126,6 +145,7 @@ func generateDeEncoder(partialName string) (err error) {
 		PkgPath:               p.PkgPath,
 		PkgName:               p.PkgName,
 		Types:                 p.StructNames,
+		Variants:              p.Variants,
 		SnakeCase:             *snakeCase,
 		LowerCamelCase:        *lowerCamelCase,
 		NoStdMarshalers:       *noStdMarshalers,
@@ -133,6 +153,9 @@ func generateDeEncoder(partialName string) (err error) {
 		OmitEmpty:             *omitEmpty,
 		LeaveTemps:            *leaveTemps,
 		DeEncoderName:         deEncoderName,
+		LazyPartials:          *lazyPartials,
+		UnsetNull:             *unset == "null",
+		FullMarshalers:        *fullMarshalers,
 		StubsOnly:             *stubs,
 		NoFormat:              *noformat,
 	}
@@ -145,9 +168,83 @@ func generateDeEncoder(partialName string) (err error) {
 	return
 }
 
+// generateSchema generates the JSON Schema of the types of the file or package fname.
+func generateSchema(fname string) (err error) {
+
+	fInfo, err := os.Stat(fname)
+	if err != nil {
+		return
+	}
+
+	p := parser.Parser{AllStructs: *allStructs}
+	if err = p.Parse(fname, fInfo.IsDir()); err != nil {
+		return fmt.Errorf("Error parsing %v: %v", fname, err)
+	}
+
+	var schemaName string
+	if fInfo.IsDir() {
+		schemaName = filepath.Join(fname, p.PkgName+"_schema.json")
+	} else {
+		if s := strings.TrimSuffix(fname, ".go"); s == fname {
+			return errors.New("Filename must end in '.go'")
+		} else {
+			schemaName = s + "_schema.json"
+		}
+	}
+	if *schemaSpecifiedName != "" {
+		schemaName = *schemaSpecifiedName
+	}
+
+	g := bootstrap.Generator{
+		BuildTags:             strings.TrimSpace(*buildTags),
+		PkgPath:               p.PkgPath,
+		PkgName:               p.PkgName,
+		Types:                 p.StructNames,
+		Enums:                 p.Enums,
+		Variants:              p.Variants,
+		SnakeCase:             *snakeCase,
+		LowerCamelCase:        *lowerCamelCase,
+		DisallowUnknownFields: *disallowUnknownFields,
+		LeaveTemps:            *leaveTemps,
+		SchemaName:            schemaName,
+	}
+
+	if err = g.RunSchema(); err != nil {
+		return fmt.Errorf("Bootstrap failed: %v", err)
+	}
+	return
+}
+
+// schemaMain runs the schema command: partialencode schema [flags] files...
+func schemaMain(args []string) {
+	flag.CommandLine.Parse(args)
+
+	files := flag.Args()
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: partialencode schema [flags] files...")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	for _, fname := range files {
+		if err := generateSchema(fname); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "schema" {
+		schemaMain(os.Args[2:])
+		return
+	}
 	flag.Parse()
 
+	if *unset != "omit" && *unset != "null" {
+		fmt.Fprintf(os.Stderr, "invalid -unset %q: must be omit or null\n", *unset)
+		os.Exit(1)
+	}
+
 	files := flag.Args()
 	files = []string{"/Users/vinodreddy/development/repos/newtb/server.go"}
 	if len(files) == 0 {
@@ -0,0 +1,253 @@
+package partialencode
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/reddyvinod/partialencode/jlexer"
+	"github.com/reddyvinod/partialencode/jwriter"
+)
+
+var (
+	// ErrPointerNotFound is returned when a JSON pointer does not reference a value in a document.
+	ErrPointerNotFound = errors.New("JSON pointer not found")
+
+	// ErrInvalidPointer is returned when a JSON pointer is not valid according to RFC 6901.
+	ErrInvalidPointer = errors.New("invalid JSON pointer")
+)
+
+// Get returns the value referenced by the JSON pointer (RFC 6901) in the document. Only the
+// values on the way to the referenced one are scanned, the rest is skipped. The returned
+// slice points into doc.
+func Get(doc []byte, ptr string) ([]byte, error) {
+	start, end, err := Locate(doc, ptr)
+	if err != nil {
+		return nil, err
+	}
+	return doc[start:end], nil
+}
+
+// Locate returns the bounds of the value referenced by the JSON pointer in the document, so
+// that doc[start:end] is the value.
+func Locate(doc []byte, ptr string) (start, end int, err error) {
+	loc, err := locate(doc, ptr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !loc.found {
+		return 0, 0, ErrPointerNotFound
+	}
+	return loc.start, loc.end, nil
+}
+
+// Set returns a copy of the document with the value referenced by the JSON pointer replaced.
+// If the value does not exist, it is added to its parent object; values can be appended to
+// arrays using the length of an array or "-" as an index.
+func Set(doc []byte, ptr string, value []byte) ([]byte, error) {
+	l := jlexer.Lexer{Data: value}
+	l.SkipRecursive()
+	l.Consumed()
+	if err := l.Error(); err != nil {
+		return nil, err
+	}
+
+	loc, err := locate(doc, ptr)
+	if err != nil {
+		return nil, err
+	}
+
+	w := jwriter.Writer{}
+	switch {
+	case loc.found:
+		w.Buffer.AppendBytes(doc[:loc.start])
+		w.Buffer.AppendBytes(value)
+		w.Buffer.AppendBytes(doc[loc.end:])
+
+	case loc.object, loc.key == "-" || loc.key == strconv.Itoa(loc.count):
+		w.Buffer.AppendBytes(doc[:loc.prevEnd])
+		if !loc.first {
+			w.RawByte(',')
+		}
+		if loc.object {
+			w.String(loc.key)
+			w.RawByte(':')
+		}
+		w.Buffer.AppendBytes(value)
+		w.Buffer.AppendBytes(doc[loc.prevEnd:])
+
+	default:
+		return nil, ErrPointerNotFound
+	}
+	return w.BuildBytes()
+}
+
+// Delete returns a copy of the document with the value referenced by the JSON pointer
+// removed from its parent object or array.
+func Delete(doc []byte, ptr string) ([]byte, error) {
+	loc, err := locate(doc, ptr)
+	if err != nil {
+		return nil, err
+	}
+	if !loc.found {
+		return nil, ErrPointerNotFound
+	}
+	if ptr == "" {
+		return nil, ErrInvalidPointer
+	}
+
+	// Remove the member together with a comma separating it from the neighbours.
+	start, end := loc.memberStart, loc.end
+	if next := skipSpace(doc, end); next < len(doc) && doc[next] == ',' {
+		end = skipSpace(doc, next+1)
+	} else if !loc.first {
+		start = loc.prevEnd
+	}
+
+	w := jwriter.Writer{}
+	w.Buffer.AppendBytes(doc[:start])
+	w.Buffer.AppendBytes(doc[end:])
+	return w.BuildBytes()
+}
+
+// pointerLocation describes where the value referenced by a JSON pointer is, or where it
+// would be added to its parent.
+type pointerLocation struct {
+	found       bool
+	start, end  int // Bounds of the value.
+	memberStart int // Start of the object member or array element.
+
+	object  bool   // Whether the parent is an object.
+	key     string // Last reference token of the pointer.
+	count   int    // Number of elements in the parent before the value.
+	first   bool   // Whether the value is the first in its parent.
+	prevEnd int    // End of the previous value or the position after the opening delimiter.
+}
+
+// locate looks up the value referenced by the JSON pointer. It returns ErrPointerNotFound if
+// the parent of the value does not exist and a location with found set to false if only the
+// value itself is missing.
+func locate(doc []byte, ptr string) (pointerLocation, error) {
+	refs, err := parsePointer(ptr)
+	if err != nil {
+		return pointerLocation{}, err
+	}
+
+	l := jlexer.Lexer{Data: doc}
+	loc := pointerLocation{found: true}
+	for i, ref := range refs {
+		loc = pointerLocation{key: ref, first: true}
+
+		switch l.PeekKind() {
+		case jlexer.Object:
+			loc.object = true
+			l.Delim('{')
+			loc.prevEnd = l.GetPos()
+			for !l.IsDelim('}') {
+				key := l.NextToken()
+				if string(key.Value) == ref {
+					loc.found = true
+					loc.memberStart = key.Offset
+					break
+				}
+				l.SkipRecursive()
+				loc.prevEnd = l.GetPos()
+				loc.first = false
+				loc.count++
+				l.WantComma()
+			}
+
+		case jlexer.Array:
+			index := parseIndex(ref)
+			l.Delim('[')
+			loc.prevEnd = l.GetPos()
+			for !l.IsDelim(']') {
+				if loc.count == index {
+					loc.found = true
+					break
+				}
+				l.SkipRecursive()
+				loc.prevEnd = l.GetPos()
+				loc.first = false
+				loc.count++
+				l.WantComma()
+			}
+
+		default:
+			if err := l.Error(); err != nil {
+				return loc, err
+			}
+			return loc, ErrPointerNotFound
+		}
+
+		if err := l.Error(); err != nil {
+			return loc, err
+		}
+		if !loc.found {
+			if i == len(refs)-1 {
+				return loc, nil
+			}
+			return loc, ErrPointerNotFound
+		}
+	}
+
+	raw := l.Raw()
+	if err := l.Error(); err != nil {
+		return loc, err
+	}
+	loc.end = l.GetPos()
+	loc.start = loc.end - len(raw)
+	if !loc.object {
+		loc.memberStart = loc.start
+	}
+	return loc, nil
+}
+
+// parsePointer splits a JSON pointer into unescaped reference tokens.
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, ErrInvalidPointer
+	}
+
+	refs := strings.Split(ptr[1:], "/")
+	for i, ref := range refs {
+		if !strings.Contains(ref, "~") {
+			continue
+		}
+		for j := 0; j < len(ref); j++ {
+			if ref[j] == '~' && (j+1 == len(ref) || (ref[j+1] != '0' && ref[j+1] != '1')) {
+				return nil, ErrInvalidPointer
+			}
+		}
+		refs[i] = strings.Replace(strings.Replace(ref, "~1", "/", -1), "~0", "~", -1)
+	}
+	return refs, nil
+}
+
+// parseIndex parses an array index reference token, returning -1 if it is not a valid index.
+func parseIndex(ref string) int {
+	if ref == "" || (len(ref) > 1 && ref[0] == '0') {
+		return -1
+	}
+	for _, c := range ref {
+		if c < '0' || c > '9' {
+			return -1
+		}
+	}
+	index, err := strconv.Atoi(ref)
+	if err != nil {
+		return -1
+	}
+	return index
+}
+
+// skipSpace returns the position of the first non-whitespace character starting from pos.
+func skipSpace(doc []byte, pos int) int {
+	for pos < len(doc) && (doc[pos] == ' ' || doc[pos] == '\t' || doc[pos] == '\r' || doc[pos] == '\n') {
+		pos++
+	}
+	return pos
+}
@@ -0,0 +1,103 @@
+package partialencode
+
+import (
+	"testing"
+)
+
+const pointerDoc = `{"a": {"b": [10, 20, {"c": "x"}]}, "m~n": 1, "s/t": 2, "e": {}, "f": []}`
+
+func TestGet(t *testing.T) {
+	for i, test := range []struct {
+		ptr     string
+		want    string
+		wantErr error
+	}{
+		{ptr: "", want: pointerDoc},
+		{ptr: "/a/b", want: `[10, 20, {"c": "x"}]`},
+		{ptr: "/a/b/1", want: `20`},
+		{ptr: "/a/b/2/c", want: `"x"`},
+		{ptr: "/m~0n", want: `1`},
+		{ptr: "/s~1t", want: `2`},
+		{ptr: "/e", want: `{}`},
+
+		{ptr: "/x", wantErr: ErrPointerNotFound},
+		{ptr: "/a/b/3", wantErr: ErrPointerNotFound},
+		{ptr: "/a/b/01", wantErr: ErrPointerNotFound},
+		{ptr: "/a/b/-", wantErr: ErrPointerNotFound},
+		{ptr: "/a/b/1/c", wantErr: ErrPointerNotFound},
+		{ptr: "/x/y", wantErr: ErrPointerNotFound},
+		{ptr: "a", wantErr: ErrInvalidPointer},
+		{ptr: "/a~2", wantErr: ErrInvalidPointer},
+	} {
+		got, err := Get([]byte(pointerDoc), test.ptr)
+		if err != test.wantErr {
+			t.Errorf("[%d, %q] Get() error = %v; want %v", i, test.ptr, err, test.wantErr)
+		} else if string(got) != test.want {
+			t.Errorf("[%d, %q] Get() = %s; want %s", i, test.ptr, got, test.want)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	for i, test := range []struct {
+		doc   string
+		ptr   string
+		value string
+		want  string
+	}{
+		{doc: `{"a": 1, "b": 2}`, ptr: "/a", value: `[1]`, want: `{"a": [1], "b": 2}`},
+		{doc: `{"a": 1, "b": 2}`, ptr: "/c", value: `3`, want: `{"a": 1, "b": 2,"c":3}`},
+		{doc: `{}`, ptr: "/c", value: `3`, want: `{"c":3}`},
+		{doc: `{"a": [1, 2]}`, ptr: "/a/0", value: `"x"`, want: `{"a": ["x", 2]}`},
+		{doc: `{"a": [1, 2]}`, ptr: "/a/-", value: `3`, want: `{"a": [1, 2,3]}`},
+		{doc: `{"a": []}`, ptr: "/a/0", value: `3`, want: `{"a": [3]}`},
+		{doc: `{"a": 1}`, ptr: "", value: `null`, want: `null`},
+	} {
+		got, err := Set([]byte(test.doc), test.ptr, []byte(test.value))
+		if err != nil {
+			t.Errorf("[%d, %q] Set() error: %v", i, test.ptr, err)
+		} else if string(got) != test.want {
+			t.Errorf("[%d, %q] Set() = %s; want %s", i, test.ptr, got, test.want)
+		}
+	}
+
+	if _, err := Set([]byte(`{"a": [1]}`), "/a/5", []byte(`1`)); err != ErrPointerNotFound {
+		t.Errorf("Set() error = %v; want %v", err, ErrPointerNotFound)
+	}
+	if _, err := Set([]byte(`{"a": 1}`), "/a", []byte(`{`)); err == nil {
+		t.Errorf("Set() with invalid value ok; want error")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	for i, test := range []struct {
+		doc  string
+		ptr  string
+		want string
+	}{
+		{doc: `{"a": 1, "b": 2, "c": 3}`, ptr: "/a", want: `{"b": 2, "c": 3}`},
+		{doc: `{"a": 1, "b": 2, "c": 3}`, ptr: "/b", want: `{"a": 1, "c": 3}`},
+		{doc: `{"a": 1, "b": 2, "c": 3}`, ptr: "/c", want: `{"a": 1, "b": 2}`},
+		{doc: `{"a": 1}`, ptr: "/a", want: `{}`},
+		{doc: `{"a": [1, [2], 3]}`, ptr: "/a/1", want: `{"a": [1, 3]}`},
+	} {
+		got, err := Delete([]byte(test.doc), test.ptr)
+		if err != nil {
+			t.Errorf("[%d, %q] Delete() error: %v", i, test.ptr, err)
+		} else if string(got) != test.want {
+			t.Errorf("[%d, %q] Delete() = %s; want %s", i, test.ptr, got, test.want)
+		}
+	}
+}
+
+func TestLocate(t *testing.T) {
+	doc := []byte(pointerDoc)
+
+	start, end, err := Locate(doc, "/a/b/2")
+	if err != nil {
+		t.Fatalf("Locate() error: %v", err)
+	}
+	if got := string(doc[start:end]); got != `{"c": "x"}` {
+		t.Errorf("Locate() = %q; want %q", got, `{"c": "x"}`)
+	}
+}
@@ -20,7 +20,8 @@ func (v *RawMessage) MarshalPartialJSON(w *jwriter.Writer) {
 
 // UnMarshalPartialJSON does JSON unmarshaling using partialencode interface.
 func (v *RawMessage) UnMarshalPartialJSON(l *jlexer.Lexer) {
-	*v = RawMessage(l.Raw())
+	// Raw data may point to the lexer window in streaming mode, so it is copied.
+	*v = append(RawMessage(nil), l.Raw()...)
 }
 
 // UnmarshalJSON implements encoding/json.Unmarshaler interface.
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/reddyvinod/partialencode"
+)
+
+func TestChangesSkipNoOps(t *testing.T) {
+	checkProperty(t, func(r *rand.Rand) error {
+		ps, err := randPartialComposeUsers(r, 2)
+		if err != nil {
+			return err
+		}
+		base, p := ps[0], ps[1]
+
+		orig, v := newComposeUser(base), newComposeUser(base)
+		ApplyPartialComposeUser(&v, p)
+
+		cs := ChangesPartialComposeUser(orig, p)
+		if unchanged := reflect.DeepEqual(orig, v); unchanged != (len(cs) == 0) {
+			return fmt.Errorf("unchanged %v, changes %+v", unchanged, cs)
+		}
+		return nil
+	})
+}
+
+func TestChanges(t *testing.T) {
+	age := 3
+	orig := ComposeUser{Name: "a", Age: &age, Backup: &ComposeAddress{City: "b"}, Password: "secret", Rank: 1}
+	var p PartialComposeUser
+	err := p.UnmarshalJSON([]byte(`{"name":"a","age":null,"backup":{"city":"c"},"address":{"city":""},"home":{"city":"d"},"password":"hunter2","rank":"2"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, c := range ChangesPartialComposeUser(orig, p) {
+		data, err := partialencode.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(data))
+	}
+	// Values are written by the generated encoders, with the options of their fields.
+	want := []string{
+		`{"path":"$.age","op":"null","old":3,"new":null}`,
+		`{"path":"$.backup.city","op":"set","old":"b","new":"c"}`,
+		`{"path":"$.home","op":"set","old":{"city":""},"new":{"city":"d"}}`,
+		`{"path":"$.password","op":"set","old":"***","new":"***"}`,
+		`{"path":"$.rank","op":"set","old":"1","new":"2"}`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangesPartialComposeUser() = %q; want %q", got, want)
+	}
+}
+
+func TestChangesTime(t *testing.T) {
+	// Times are compared by the instant they represent, not by their location.
+	orig := ComposeUser{Seen: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)}
+	var p PartialComposeUser
+	if err := p.UnmarshalJSON([]byte(`{"seen":"2020-01-01T14:00:00+02:00"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if cs := ChangesPartialComposeUser(orig, p); len(cs) != 0 {
+		t.Errorf("ChangesPartialComposeUser() = %+v; want none", cs)
+	}
+}
@@ -0,0 +1,50 @@
+package tests
+
+import "time"
+
+type ComposeShape interface{ Area() float64 }
+
+//partialencode:variant ComposeShape type=circle
+type ComposeCircle struct {
+	R float64 `json:"r"`
+}
+
+func (c *ComposeCircle) Area() float64 { return 3 * c.R * c.R }
+
+//partialencode:variant ComposeShape type=square
+type ComposeSquare struct {
+	S     float64        `json:"s"`
+	Inner *ComposeCircle `json:"inner"`
+}
+
+func (s *ComposeSquare) Area() float64 { return s.S * s.S }
+
+type ComposeUser struct {
+	Name    string                     `json:"name"`
+	Age     *int                       `json:"age"`
+	Tags    []string                   `json:"tags"`
+	Address ComposeAddress             `json:"address"`
+	Backup  *ComposeAddress            `json:"backup"`
+	Home    ComposeAddress             `json:"home" partial:"replace"`
+	Others  []ComposeAddress           `json:"others"`
+	ByName  map[string]*ComposeAddress `json:"by_name"`
+	Shape   ComposeShape               `json:"shape"`
+	Anon    struct {
+		X int  `json:"x"`
+		Y *int `json:"y"`
+	} `json:"anon"`
+	Password string    `json:"password" partial:"sensitive"`
+	Rank     int       `json:"rank,string"`
+	Seen     time.Time `json:"seen"`
+}
+
+type ComposeAddress struct {
+	City string      `json:"city"`
+	Zip  *string     `json:"zip"`
+	Geo  *ComposeGeo `json:"geo"`
+}
+
+type ComposeGeo struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// randObject returns a random JSON object with a random subset of the members, which are set
+// to null or to a random value.
+func randObject(r *rand.Rand, members map[string]func(*rand.Rand) string) string {
+	keys := make([]string, 0, len(members))
+	for k := range members {
+		keys = append(keys, k)
+	}
+	// Map order is random, sort for reproducible seeds.
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		switch r.Intn(3) {
+		case 0:
+		case 1:
+			parts = append(parts, fmt.Sprintf("%q:null", k))
+		default:
+			parts = append(parts, fmt.Sprintf("%q:%s", k, members[k](r)))
+		}
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func randString(r *rand.Rand) string { return fmt.Sprintf("%q", []string{"a", "b", ""}[r.Intn(3)]) }
+func randNumber(r *rand.Rand) string { return fmt.Sprint(r.Intn(3)) }
+
+func randArray(elem func(*rand.Rand) string) func(*rand.Rand) string {
+	return func(r *rand.Rand) string {
+		var elems []string
+		for i := r.Intn(3); i > 0; i-- {
+			elems = append(elems, elem(r))
+		}
+		return "[" + strings.Join(elems, ",") + "]"
+	}
+}
+
+func randGeo(r *rand.Rand) string {
+	return randObject(r, map[string]func(*rand.Rand) string{"lat": randNumber, "lng": randNumber})
+}
+
+func randAddress(r *rand.Rand) string {
+	return randObject(r, map[string]func(*rand.Rand) string{"city": randString, "zip": randString, "geo": randGeo})
+}
+
+func randShape(r *rand.Rand) string {
+	switch r.Intn(3) {
+	case 0:
+		return `{"type":"circle","r":` + randNumber(r) + `}`
+	case 1:
+		return `{"type":"square","s":` + randNumber(r) + `,"inner":{"type":"circle","r":` + randNumber(r) + `}}`
+	}
+	return `{"type":"square","s":` + randNumber(r) + `}`
+}
+
+func randComposeUser(r *rand.Rand) string {
+	return randObject(r, map[string]func(*rand.Rand) string{
+		"name":    randString,
+		"age":     randNumber,
+		"tags":    randArray(randString),
+		"address": randAddress,
+		"backup":  randAddress,
+		"home":    randAddress,
+		"others":  randArray(randAddress),
+		"by_name": func(r *rand.Rand) string { return `{"k":` + randAddress(r) + `}` },
+		"shape":   randShape,
+		"anon": func(r *rand.Rand) string {
+			return randObject(r, map[string]func(*rand.Rand) string{"x": randNumber, "y": randNumber})
+		},
+		"password": randString,
+	})
+}
+
+// randPartialComposeUsers returns n random partials of ComposeUser.
+func randPartialComposeUsers(r *rand.Rand, n int) ([]PartialComposeUser, error) {
+	ps := make([]PartialComposeUser, n)
+	for i := range ps {
+		if err := ps[i].UnmarshalJSON([]byte(randComposeUser(r))); err != nil {
+			return nil, err
+		}
+	}
+	return ps, nil
+}
+
+// newComposeUser returns a ComposeUser with the partial base applied. Nested structs are
+// patched in place, so each value a property compares is built from base separately.
+func newComposeUser(base PartialComposeUser) ComposeUser {
+	var v ComposeUser
+	ApplyPartialComposeUser(&v, base)
+	return v
+}
+
+// checkProperty checks that prop holds for random inputs, reporting the seed of the cases
+// that fail it with the returned error.
+func checkProperty(t *testing.T, prop func(r *rand.Rand) error) {
+	t.Helper()
+	f := func(seed int64) bool {
+		if err := prop(rand.New(rand.NewSource(seed))); err != nil {
+			t.Logf("seed %d: %v", seed, err)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestComposeApply(t *testing.T) {
+	checkProperty(t, func(r *rand.Rand) error {
+		ps, err := randPartialComposeUsers(r, 1+r.Intn(5))
+		if err != nil {
+			return err
+		}
+		base, ps := ps[0], ps[1:]
+
+		inOrder, composed := newComposeUser(base), newComposeUser(base)
+		for _, p := range ps {
+			ApplyPartialComposeUser(&inOrder, p)
+		}
+		ApplyPartialComposeUser(&composed, ComposePartialComposeUser(ps...))
+
+		if !reflect.DeepEqual(inOrder, composed) {
+			return fmt.Errorf("applied in order %+v, composed %+v", inOrder, composed)
+		}
+		return nil
+	})
+}
+
+func TestComposeAssociative(t *testing.T) {
+	checkProperty(t, func(r *rand.Rand) error {
+		ps, err := randPartialComposeUsers(r, 3)
+		if err != nil {
+			return err
+		}
+		a, b, c := ps[0], ps[1], ps[2]
+
+		var left, right ComposeUser
+		ApplyPartialComposeUser(&left, ComposePartialComposeUser(ComposePartialComposeUser(a, b), c))
+		ApplyPartialComposeUser(&right, ComposePartialComposeUser(a, ComposePartialComposeUser(b, c)))
+		if !reflect.DeepEqual(left, right) {
+			return fmt.Errorf("(a+b)+c applied %+v, a+(b+c) applied %+v", left, right)
+		}
+		return nil
+	})
+}
+
+func TestComposeNested(t *testing.T) {
+	var a, b PartialComposeUser
+	if err := a.UnmarshalJSON([]byte(`{"address":{"city":"x"},"home":{"city":"x"},"backup":null}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.UnmarshalJSON([]byte(`{"address":{"zip":"y"},"home":{"zip":"y"},"backup":{"city":"z"}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	p := ComposePartialComposeUser(a, b)
+	if p.Address.City != "x" || p.Address.Zip == nil || *p.Address.Zip != "y" {
+		t.Errorf("Address = %+v; want both changes", p.Address)
+	}
+	if p.Home.PartialValid.City || p.Home.Zip == nil || *p.Home.Zip != "y" {
+		t.Errorf("Home = %+v; want the later partial only", p.Home)
+	}
+	if p.Backup == nil || p.Backup.City != "z" || !p.Backup.PartialSet.Zip || !p.Backup.PartialSet.Geo {
+		t.Errorf("Backup = %+v; want the patch of a reset address", p.Backup)
+	}
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestDeepCopy(t *testing.T) {
+	checkProperty(t, func(r *rand.Rand) error {
+		ps, err := randPartialComposeUsers(r, 2)
+		if err != nil {
+			return err
+		}
+		base, p := ps[0], ps[1]
+
+		v, want := newComposeUser(base), newComposeUser(base)
+		c := v.DeepCopy()
+		if !reflect.DeepEqual(c, v) || !c.Equal(v) {
+			return fmt.Errorf("copy %+v of %+v", c, v)
+		}
+		ApplyPartialComposeUser(&c, p)
+		if !reflect.DeepEqual(v, want) {
+			return fmt.Errorf("%+v changed to %+v by its copy", want, v)
+		}
+
+		pc := p.DeepCopy()
+		if !reflect.DeepEqual(pc, p) || !pc.Equal(p) || !pc.PartialValid.Equal(p.PartialValid) {
+			return fmt.Errorf("copy %+v of %+v", pc, p)
+		}
+		return nil
+	})
+}
+
+func TestEqual(t *testing.T) {
+	checkProperty(t, func(r *rand.Rand) error {
+		ps, err := randPartialComposeUsers(r, 2)
+		if err != nil {
+			return err
+		}
+		a, b := ps[0], ps[1]
+		if a.Equal(b) != reflect.DeepEqual(a, b) {
+			return fmt.Errorf("Equal(%+v, %+v) = %v", a, b, a.Equal(b))
+		}
+
+		v, w := newComposeUser(a), newComposeUser(b)
+		if v.Equal(w) != reflect.DeepEqual(v, w) {
+			return fmt.Errorf("Equal(%+v, %+v) = %v", v, w, v.Equal(w))
+		}
+		return nil
+	})
+}
+
+func TestEqualFlags(t *testing.T) {
+	var a, b PartialComposeUser
+	a.PartialSet.Name = true
+	if a.Equal(b) {
+		t.Error("Equal() = true for partials with different flags")
+	}
+	b.PartialValid.Name = true
+	if a.Equal(b) {
+		t.Error("Equal() = true for a null and a valid field")
+	}
+}
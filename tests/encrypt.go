@@ -0,0 +1,13 @@
+package tests
+
+type EncryptedAccount struct {
+	Name  string         `json:"name"`
+	SSN   string         `json:"ssn" partial:"encrypt=pii"`
+	Card  *EncryptedCard `json:"card" partial:"encrypt=pii"`
+	Notes []string       `json:"notes,omitempty" partial:"encrypt=notes;sensitive"`
+}
+
+type EncryptedCard struct {
+	Number string `json:"number"`
+	Expiry string `json:"expiry"`
+}
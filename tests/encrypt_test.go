@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/reddyvinod/partialencode"
+	"github.com/reddyvinod/partialencode/jwriter"
+)
+
+// xorCipher is a deterministic cipher that prefixes the ciphertext with the associated data.
+type xorCipher byte
+
+func (c xorCipher) Encrypt(plaintext, ad []byte) ([]byte, error) {
+	out := append(append([]byte(nil), ad...), 0)
+	for _, b := range plaintext {
+		out = append(out, b^byte(c))
+	}
+	return out, nil
+}
+
+func (c xorCipher) Decrypt(ciphertext, ad []byte) ([]byte, error) {
+	prefix := append(append([]byte(nil), ad...), 0)
+	if !bytes.HasPrefix(ciphertext, prefix) {
+		return nil, errors.New("associated data mismatch")
+	}
+	out := make([]byte, 0, len(ciphertext)-len(prefix))
+	for _, b := range ciphertext[len(prefix):] {
+		out = append(out, b^byte(c))
+	}
+	return out, nil
+}
+
+func registerTestCiphers() {
+	partialencode.RegisterFieldCipher("pii", xorCipher(0x5a))
+	partialencode.RegisterFieldCipher("notes", xorCipher(0x33))
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	registerTestCiphers()
+
+	var plain PartialEncryptedAccount
+	plain.Name, plain.SSN = "a", "123-45"
+	plain.Card = &PartialEncryptedCard{Number: "4111", Expiry: "12/30"}
+	plain.Card.PartialValid.Number, plain.Card.PartialValid.Expiry = true, true
+	plain.Notes = []string{"x"}
+	plain.PartialValid.Name, plain.PartialValid.SSN, plain.PartialValid.Card, plain.PartialValid.Notes = true, true, true, true
+
+	data, err := plain.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"123-45", "4111", `"x"`} {
+		if strings.Contains(string(data), s) {
+			t.Errorf("%s contains %s", data, s)
+		}
+	}
+
+	var got PartialEncryptedAccount
+	if err := got.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, plain) {
+		t.Errorf("got %+v; want %+v", got, plain)
+	}
+}
+
+func TestEncryptPartial(t *testing.T) {
+	registerTestCiphers()
+
+	var p PartialEncryptedAccount
+	if err := p.UnmarshalJSON([]byte(`{"ssn":null}`)); err != nil {
+		t.Fatal(err)
+	}
+	if !p.PartialSet.SSN || p.PartialValid.SSN || p.PartialValid.Card {
+		t.Errorf("got flags %+v, %+v", p.PartialValid, p.PartialSet)
+	}
+
+	// Unset fields are not encrypted, and null is written as is.
+	partialencode.RegisterFieldCipher("pii", nil)
+	defer registerTestCiphers()
+	data, err := p.MarshalJSON()
+	if err != nil || string(data) != `{}` {
+		t.Errorf("got %s, %v", data, err)
+	}
+	p.PartialValid.SSN = true
+	if _, err := p.MarshalJSON(); err == nil {
+		t.Errorf("no error without cipher")
+	}
+}
+
+func TestEncryptAssociatedData(t *testing.T) {
+	registerTestCiphers()
+
+	var p PartialEncryptedAccount
+	p.SSN, p.PartialValid.SSN = "123-45", true
+	data, err := p.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The ciphertext of a field does not decrypt as another field with the same cipher.
+	moved := bytes.Replace(data, []byte(`"ssn"`), []byte(`"card"`), 1)
+	var q PartialEncryptedAccount
+	err = q.UnmarshalJSON(moved)
+	if err == nil || !strings.Contains(err.Error(), "EncryptedAccount.card") {
+		t.Errorf("got error %v for %s", err, moved)
+	}
+}
+
+func TestEncryptRedacted(t *testing.T) {
+	registerTestCiphers()
+
+	var p PartialEncryptedAccount
+	p.Notes, p.PartialValid.Notes = []string{"x"}, true
+	w := jwriter.Writer{RedactSensitive: jwriter.RedactPlaceholder}
+	p.MarshalPartialJSON(&w)
+	if got := string(w.Buffer.BuildBytes()); got != `{"notes":"***"}` {
+		t.Errorf("got %s", got)
+	}
+}
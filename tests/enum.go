@@ -0,0 +1,25 @@
+package tests
+
+//partialencode:enum
+type EnumStatus string
+
+const (
+	EnumActive   EnumStatus = "active"
+	EnumInactive EnumStatus = "inactive"
+	EnumDefault             = EnumActive
+)
+
+//partialencode:enum fallback=EnumPriorityUnknown
+type EnumPriority int
+
+const (
+	EnumPriorityUnknown EnumPriority = iota
+	EnumPriorityLow
+	EnumPriorityHigh
+)
+
+type EnumTask struct {
+	Status     EnumStatus     `json:"status"`
+	Priority   EnumPriority   `json:"priority"`
+	Priorities []EnumPriority `json:"priorities"`
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/reddyvinod/partialencode/jlexer"
+)
+
+func TestEnumValues(t *testing.T) {
+	if got, want := EnumStatus("").Values(), []EnumStatus{EnumActive, EnumInactive}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Values() = %v; want %v", got, want)
+	}
+	if !EnumActive.IsValid() || EnumStatus("gone").IsValid() {
+		t.Errorf("IsValid() does not match the declared values")
+	}
+	if !EnumPriorityHigh.IsValid() || EnumPriority(7).IsValid() {
+		t.Errorf("IsValid() does not match the declared values")
+	}
+}
+
+func TestEnumRoundTrip(t *testing.T) {
+	in := `{"status":"inactive","priority":"EnumPriorityHigh","priorities":["EnumPriorityLow",2]}`
+
+	var v PartialEnumTask
+	if err := v.UnmarshalJSON([]byte(in)); err != nil {
+		t.Fatal(err)
+	}
+	if v.Status != EnumInactive || v.Priority != EnumPriorityHigh || !reflect.DeepEqual(v.Priorities, []EnumPriority{EnumPriorityLow, EnumPriorityHigh}) {
+		t.Errorf("UnmarshalJSON() = %+v; want the declared values", v)
+	}
+
+	// Integer enums are written by the names of their constants.
+	out, err := v.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"status":"inactive","priority":"EnumPriorityHigh","priorities":["EnumPriorityLow","EnumPriorityHigh"]}`
+	if string(out) != want {
+		t.Errorf("MarshalJSON() = %s; want %s", out, want)
+	}
+
+	// Values without a name are written as numbers.
+	out, err = EnumPriority(7).MarshalJSON()
+	if err != nil || string(out) != "7" {
+		t.Errorf("MarshalJSON() = %s, %v; want 7", out, err)
+	}
+}
+
+func TestEnumUnknown(t *testing.T) {
+	// Without a fallback, unknown values are errors.
+	var s EnumStatus
+	err := s.UnmarshalJSON([]byte(`"gone"`))
+	if lerr, ok := err.(*jlexer.LexerError); !ok || lerr.Reason != "unknown EnumStatus value" {
+		t.Errorf("UnmarshalJSON() error: %v; want unknown value", err)
+	}
+
+	// With a fallback, unknown names and numbers are decoded to it.
+	for _, in := range []string{`"EnumPriorityUrgent"`, `9`} {
+		p := EnumPriorityHigh
+		if err := p.UnmarshalJSON([]byte(in)); err != nil || p != EnumPriorityUnknown {
+			t.Errorf("UnmarshalJSON(%s) = %v, %v; want the fallback", in, p, err)
+		}
+	}
+
+	// Fields of unknown values are reported at their paths.
+	var v PartialEnumTask
+	err = v.UnmarshalJSON([]byte(`{"priority":"EnumPriorityUrgent","status":"gone"}`))
+	if lerr, ok := err.(*jlexer.LexerError); !ok || lerr.Path != "$.status" {
+		t.Errorf("UnmarshalJSON() error: %v; want error at $.status", err)
+	}
+	if v.Priority != EnumPriorityUnknown {
+		t.Errorf("Priority = %v; want the fallback", v.Priority)
+	}
+}
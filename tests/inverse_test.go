@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestInverseRestores(t *testing.T) {
+	checkProperty(t, func(r *rand.Rand) error {
+		ps, err := randPartialComposeUsers(r, 2)
+		if err != nil {
+			return err
+		}
+		base, p := ps[0], ps[1]
+
+		orig, v := newComposeUser(base), newComposeUser(base)
+		inv := InversePartialComposeUser(orig, p)
+		ApplyPartialComposeUser(&v, p)
+		ApplyPartialComposeUser(&v, inv)
+
+		if !reflect.DeepEqual(orig, v) {
+			return fmt.Errorf("original %+v, restored %+v", orig, v)
+		}
+		return nil
+	})
+}
+
+func TestInverseTouchesChangedFields(t *testing.T) {
+	zip := "z"
+	orig := ComposeUser{Name: "a", Backup: &ComposeAddress{City: "b", Zip: &zip}}
+	var p PartialComposeUser
+	if err := p.UnmarshalJSON([]byte(`{"name":"","age":1,"backup":{"city":"c"},"address":{"city":"d"}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	inv := InversePartialComposeUser(orig, p)
+	if !inv.PartialValid.Name || inv.Name != "a" {
+		t.Errorf("Name = %q, valid %v; want the original value", inv.Name, inv.PartialValid.Name)
+	}
+	if !inv.PartialSet.Age || inv.PartialValid.Age {
+		t.Error("Age is not set to null")
+	}
+	if inv.Backup == nil || inv.Backup.City != "b" || inv.Backup.PartialValid.Zip || inv.Backup.PartialSet.Zip {
+		t.Errorf("Backup = %+v; want the original city only", inv.Backup)
+	}
+	if !inv.Address.PartialSet.City || inv.Address.PartialValid.Zip {
+		t.Errorf("Address = %+v; want the city set to null", inv.Address)
+	}
+	if inv.PartialValid.Tags || inv.PartialSet.Tags || inv.PartialValid.Home || inv.PartialSet.Home {
+		t.Error("untouched fields are in the inverse")
+	}
+}
@@ -0,0 +1,18 @@
+package tests
+
+type LazyOrder struct {
+	ID      int          `json:"id"`
+	Note    string       `json:"note" default:"none"`
+	Items   []LazyItem   `json:"items"`
+	Address *LazyAddress `json:"address"`
+	Extra   interface{}  `json:"extra"`
+}
+
+type LazyItem struct {
+	SKU string `json:"sku"`
+	Qty int    `json:"qty"`
+}
+
+type LazyAddress struct {
+	City string `json:"city"`
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/reddyvinod/partialencode"
+	"github.com/reddyvinod/partialencode/jlexer"
+)
+
+const lazyOrderJSON = `{"id": 7, "items": [ {"sku": "a", "qty": 2} ], "address": null, "extra": {"x": [1, 2]}}`
+
+func TestLazyGetters(t *testing.T) {
+	var v LazyPartialLazyOrder
+	if err := v.UnmarshalJSON([]byte(lazyOrderJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !v.PartialValid.ID || !v.PartialValid.Items || v.PartialValid.Address || !v.PartialSet.Address || v.PartialValid.Note {
+		t.Errorf("flags = %+v, %+v; want the flags of the decoded fields", v.PartialValid, v.PartialSet)
+	}
+
+	id, err := v.GetID()
+	if err != nil || id != 7 {
+		t.Errorf("GetID() = %v, %v; want 7", id, err)
+	}
+	items, err := v.GetItems()
+	if err != nil || len(items) != 1 || items[0].SKU != "a" || items[0].Qty != 2 || !items[0].PartialValid.Qty {
+		t.Errorf("GetItems() = %+v, %v; want one item", items, err)
+	}
+	if address, err := v.GetAddress(); err != nil || address != nil {
+		t.Errorf("GetAddress() = %+v, %v; want nil", address, err)
+	}
+	if note, err := v.GetNote(); err != nil || note != "none" {
+		t.Errorf("GetNote() = %q, %v; want the default", note, err)
+	}
+
+	p, err := v.Partial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != 7 || len(p.Items) != 1 || p.Note != "none" || p.PartialValid != v.PartialValid {
+		t.Errorf("Partial() = %+v; want the decoded fields", p)
+	}
+}
+
+func TestLazyErrors(t *testing.T) {
+	var v LazyPartialLazyOrder
+	if err := v.UnmarshalJSON([]byte(`{"id": "x", "items": [1]}`)); err != nil {
+		t.Fatalf("UnmarshalJSON() error: %v; want errors on access", err)
+	}
+	if _, err := v.GetID(); err == nil {
+		t.Errorf("GetID() ok; want error")
+	}
+	if _, err := v.GetItems(); err == nil {
+		t.Errorf("GetItems() ok; want error")
+	}
+}
+
+func TestLazyEncodeRaw(t *testing.T) {
+	var v LazyPartialLazyOrder
+	if err := v.UnmarshalJSON([]byte(lazyOrderJSON)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.GetID(); err != nil {
+		t.Fatal(err)
+	}
+	v.SetNote("fragile")
+
+	// Untouched fields are written as they were decoded, decoded and set ones are encoded.
+	got, err := partialencode.Marshal(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":7,"note":"fragile","items":[ {"sku": "a", "qty": 2} ],"extra":{"x": [1, 2]}}`
+	if string(got) != want {
+		t.Errorf("Marshal() = %s; want %s", got, want)
+	}
+}
+
+func TestLazyStream(t *testing.T) {
+	var v LazyPartialLazyOrder
+	r := iotest.OneByteReader(strings.NewReader(lazyOrderJSON))
+	if err := partialencode.UnmarshalFromReader(r, &v); err != nil {
+		t.Fatal(err)
+	}
+
+	// The raw values are copied out of the window of the stream.
+	items, err := v.GetItems()
+	if err != nil || len(items) != 1 || items[0].SKU != "a" {
+		t.Errorf("GetItems() = %+v, %v; want one item", items, err)
+	}
+	got, err := partialencode.Marshal(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":7,"items":[{"sku":"a","qty":2}],"extra":{"x": [1, 2]}}`
+	if string(got) != want {
+		t.Errorf("Marshal() = %s; want %s", got, want)
+	}
+}
+
+func TestLazyLexerOptions(t *testing.T) {
+	// Raw values are checked against the limits while decoding.
+	var v LazyPartialLazyOrder
+	l := jlexer.Lexer{Data: []byte(`{"extra": [[[1]]]}`), MaxDepth: 3}
+	v.UnMarshalPartialJSON(&l)
+	if l.Error() == nil {
+		t.Errorf("UnMarshalPartialJSON() ok; want error of MaxDepth")
+	}
+
+	// Raw values are decoded with the options of the lexer.
+	l = jlexer.Lexer{Data: []byte(`{"extra": 1.50}`), UseNumber: true}
+	v.UnMarshalPartialJSON(&l)
+	if err := l.Error(); err != nil {
+		t.Fatal(err)
+	}
+	if extra, err := v.GetExtra(); err != nil || extra != json.Number("1.50") {
+		t.Errorf("GetExtra() = %#v, %v; want json.Number", extra, err)
+	}
+}
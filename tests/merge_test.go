@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/reddyvinod/partialencode"
+)
+
+func mergeInput(t *testing.T, base string, a, b string) (ComposeUser, PartialComposeUser, PartialComposeUser) {
+	var pb, pa, pt PartialComposeUser
+	for _, v := range []struct {
+		p  *PartialComposeUser
+		in string
+	}{{&pb, base}, {&pa, a}, {&pt, b}} {
+		if err := v.p.UnmarshalJSON([]byte(v.in)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var u ComposeUser
+	ApplyPartialComposeUser(&u, pb)
+	return u, pa, pt
+}
+
+func conflictPaths(cs []partialencode.Conflict) []string {
+	var paths []string
+	for _, c := range cs {
+		paths = append(paths, c.Path)
+	}
+	return paths
+}
+
+func TestMergeDisjoint(t *testing.T) {
+	base, a, b := mergeInput(t,
+		`{"name":"a","address":{"city":"x"}}`,
+		`{"name":"b","address":{"city":"y"}}`,
+		`{"tags":["t"],"address":{"zip":"z"},"backup":{"city":"w"}}`)
+
+	p, cs := MergePartialComposeUser(base, a, b)
+	if len(cs) != 0 {
+		t.Fatalf("conflicts %v; want none", conflictPaths(cs))
+	}
+	var u ComposeUser
+	ApplyPartialComposeUser(&u, p)
+	want := ComposeUser{Name: "b", Tags: []string{"t"}, Address: ComposeAddress{City: "y", Zip: stringPtr("z")}, Backup: &ComposeAddress{City: "w"}}
+	if !reflect.DeepEqual(u, want) {
+		t.Errorf("merged %+v; want %+v", u, want)
+	}
+}
+
+func TestMergeSameChanges(t *testing.T) {
+	// Changes to the same value, and changes that keep the value of the base, are not conflicts.
+	base, a, b := mergeInput(t,
+		`{"name":"a","age":1}`,
+		`{"name":"b","age":1}`,
+		`{"name":"b","age":2}`)
+
+	p, cs := MergePartialComposeUser(base, a, b)
+	if len(cs) != 0 {
+		t.Fatalf("conflicts %v; want none", conflictPaths(cs))
+	}
+	if p.Name != "b" || p.Age == nil || *p.Age != 2 {
+		t.Errorf("merged name %q, age %v; want b and 2", p.Name, p.Age)
+	}
+}
+
+func TestMergeSameTimes(t *testing.T) {
+	// A change to the same instant in another location keeps the value of the base.
+	base, a, b := mergeInput(t,
+		`{"seen":"2020-01-01T12:00:00Z"}`,
+		`{"seen":"2020-01-01T14:00:00+02:00"}`,
+		`{"seen":"2021-01-01T12:00:00Z"}`)
+
+	p, cs := MergePartialComposeUser(base, a, b)
+	if len(cs) != 0 {
+		t.Fatalf("conflicts %v; want none", conflictPaths(cs))
+	}
+	if !p.Seen.Equal(b.Seen) {
+		t.Errorf("merged %v; want %v", p.Seen, b.Seen)
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	base, a, b := mergeInput(t,
+		`{"name":"a","age":1,"address":{"city":"x"},"backup":{"city":"x"}}`,
+		`{"name":"b","address":{"city":"y"},"backup":{"city":"y"},"age":null}`,
+		`{"name":"c","address":{"city":"z"},"backup":{"city":"z"},"age":3}`)
+
+	p, cs := MergePartialComposeUser(base, a, b)
+	want := []string{"$.name", "$.age", "$.address.city", "$.backup.city"}
+	if got := conflictPaths(cs); !reflect.DeepEqual(got, want) {
+		t.Fatalf("conflicts %v; want %v", got, want)
+	}
+	if cs[0].Ours != "b" || cs[0].Theirs != "c" {
+		t.Errorf("conflict %+v; want b and c", cs[0])
+	}
+	if cs[1].Ours != nil || *cs[1].Theirs.(*int) != 3 {
+		t.Errorf("conflict %+v; want null and 3", cs[1])
+	}
+
+	// Conflicts are settled with the values of a by default.
+	if p.Name != "b" || p.Address.City != "y" || p.Backup.City != "y" || !p.PartialSet.Age || p.PartialValid.Age {
+		t.Errorf("merged %+v; want the values of a", p)
+	}
+
+	p, _ = MergePartialComposeUserWith(base, a, b, partialencode.ResolveTheirs)
+	if p.Name != "c" || p.Address.City != "z" || p.Backup.City != "z" || p.Age == nil || *p.Age != 3 {
+		t.Errorf("merged %+v; want the values of b", p)
+	}
+
+	resolve := func(c partialencode.Conflict) partialencode.Resolution {
+		if strings.HasPrefix(c.Path, "$.address") {
+			return partialencode.Theirs
+		}
+		return partialencode.Ours
+	}
+	p, _ = MergePartialComposeUserWith(base, a, b, resolve)
+	if p.Name != "b" || p.Address.City != "z" || p.Backup.City != "y" {
+		t.Errorf("merged %+v; want the address of b only", p)
+	}
+}
+
+func TestMergeNested(t *testing.T) {
+	// Nested partials that both change are merged field by field.
+	base, a, b := mergeInput(t,
+		`{"address":{"city":"x","geo":{"lat":1}},"backup":null}`,
+		`{"address":{"city":"y","geo":{"lat":2}},"backup":{"city":"y"}}`,
+		`{"address":{"zip":"z","geo":{"lng":3}},"backup":{"zip":"z"}}`)
+
+	p, cs := MergePartialComposeUser(base, a, b)
+	if len(cs) != 0 {
+		t.Fatalf("conflicts %v; want none", conflictPaths(cs))
+	}
+	var u ComposeUser
+	ApplyPartialComposeUser(&u, p)
+	want := ComposeAddress{City: "y", Zip: stringPtr("z"), Geo: &ComposeGeo{Lat: 2, Lng: 3}}
+	if !reflect.DeepEqual(u.Address, want) {
+		t.Errorf("merged address %+v; want %+v", u.Address, want)
+	}
+	if want := (&ComposeAddress{City: "y", Zip: stringPtr("z")}); !reflect.DeepEqual(u.Backup, want) {
+		t.Errorf("merged backup %+v; want %+v", u.Backup, want)
+	}
+}
+
+func stringPtr(s string) *string { return &s }
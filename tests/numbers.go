@@ -0,0 +1,13 @@
+package tests
+
+import (
+	"encoding/json"
+	"math/big"
+)
+
+type NumbersBill struct {
+	ID    json.Number `json:"id"`
+	Total *big.Int    `json:"total"`
+	Rate  *big.Float  `json:"rate"`
+	Count big.Int     `json:"count"`
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestNumbersRoundTrip(t *testing.T) {
+	in := `{"id":12345678901234567890,"total":123456789012345678901234567890,"rate":0.1,"count":-7}`
+
+	var v PartialNumbersBill
+	if err := v.UnmarshalJSON([]byte(in)); err != nil {
+		t.Fatal(err)
+	}
+	if v.ID != "12345678901234567890" {
+		t.Errorf("ID = %q; want the literal", v.ID)
+	}
+	if want, _ := new(big.Int).SetString("123456789012345678901234567890", 10); v.Total == nil || v.Total.Cmp(want) != 0 {
+		t.Errorf("Total = %v; want %v", v.Total, want)
+	}
+	if v.Rate == nil || v.Rate.Text('g', -1) != "0.1" {
+		t.Errorf("Rate = %v; want 0.1", v.Rate)
+	}
+	if v.Count.Int64() != -7 {
+		t.Errorf("Count = %v; want -7", &v.Count)
+	}
+
+	out, err := v.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != in {
+		t.Errorf("MarshalJSON() = %s; want %s", out, in)
+	}
+}
+
+func TestNumbersNull(t *testing.T) {
+	var v PartialNumbersBill
+	if err := v.UnmarshalJSON([]byte(`{"total":null,"rate":null}`)); err != nil {
+		t.Fatal(err)
+	}
+	if v.PartialValid.Total || !v.PartialSet.Total || v.Total != nil || v.Rate != nil {
+		t.Errorf("v = %+v; want null Total and Rate", v)
+	}
+}
+
+func TestNumbersErrors(t *testing.T) {
+	for _, in := range []string{`{"id":true}`, `{"total":1.5}`, `{"rate":true}`, `{"count":"1"}`} {
+		var v PartialNumbersBill
+		if err := v.UnmarshalJSON([]byte(in)); err == nil {
+			t.Errorf("UnmarshalJSON(%s) ok; want error", in)
+		}
+	}
+}
+
+func TestNumbersInvalidLiteral(t *testing.T) {
+	v := PartialNumbersBill{ID: json.Number(`1,"admin":true`)}
+	v.PartialValid.ID = true
+	if out, err := v.MarshalJSON(); err == nil {
+		t.Errorf("MarshalJSON() = %s; want error", out)
+	}
+}
+
+func TestNumbersDeepCopy(t *testing.T) {
+	v := NumbersBill{Total: big.NewInt(1), Rate: big.NewFloat(0.5)}
+	v.Count.SetInt64(2)
+
+	c := v.DeepCopy()
+	if !c.Equal(v) {
+		t.Fatalf("copy %+v of %+v differs", c, v)
+	}
+	c.Total.SetInt64(3)
+	c.Rate.SetFloat64(1.5)
+	c.Count.SetInt64(4)
+	if v.Total.Int64() != 1 || v.Rate.Text('g', -1) != "0.5" || v.Count.Int64() != 2 {
+		t.Errorf("%v, %v, %v changed by the copy", v.Total, v.Rate, &v.Count)
+	}
+	if c.Equal(v) {
+		t.Errorf("Equal() = true for %v and %v", c.Total, v.Total)
+	}
+
+	// Values of equal numbers are equal regardless of their representation.
+	w := v.DeepCopy()
+	w.Rate = new(big.Float).SetPrec(200).SetFloat64(0.5)
+	if !w.Equal(v) {
+		t.Errorf("Equal() = false for %v and %v", w.Rate, v.Rate)
+	}
+}
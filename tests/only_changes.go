@@ -0,0 +1,13 @@
+package tests
+
+type OnlyChangesUser struct {
+	Name    string               `json:"name"`
+	Address OnlyChangesAddress   `json:"address"`
+	Backup  *OnlyChangesAddress  `json:"backup"`
+	Others  []OnlyChangesAddress `json:"others"`
+}
+
+type OnlyChangesAddress struct {
+	City string  `json:"city"`
+	Zip  *string `json:"zip"`
+}
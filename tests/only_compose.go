@@ -0,0 +1,13 @@
+package tests
+
+type OnlyComposeUser struct {
+	Name    string               `json:"name"`
+	Address OnlyComposeAddress   `json:"address"`
+	Backup  *OnlyComposeAddress  `json:"backup"`
+	Others  []OnlyComposeAddress `json:"others"`
+}
+
+type OnlyComposeAddress struct {
+	City string  `json:"city"`
+	Zip  *string `json:"zip"`
+}
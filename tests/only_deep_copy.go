@@ -0,0 +1,13 @@
+package tests
+
+type OnlyDeepCopyUser struct {
+	Name    string                `json:"name"`
+	Address OnlyDeepCopyAddress   `json:"address"`
+	Backup  *OnlyDeepCopyAddress  `json:"backup"`
+	Others  []OnlyDeepCopyAddress `json:"others"`
+}
+
+type OnlyDeepCopyAddress struct {
+	City string  `json:"city"`
+	Zip  *string `json:"zip"`
+}
@@ -0,0 +1,13 @@
+package tests
+
+type OnlyInverseUser struct {
+	Name    string               `json:"name"`
+	Address OnlyInverseAddress   `json:"address"`
+	Backup  *OnlyInverseAddress  `json:"backup"`
+	Others  []OnlyInverseAddress `json:"others"`
+}
+
+type OnlyInverseAddress struct {
+	City string  `json:"city"`
+	Zip  *string `json:"zip"`
+}
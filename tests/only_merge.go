@@ -0,0 +1,13 @@
+package tests
+
+type OnlyMergeUser struct {
+	Name    string             `json:"name"`
+	Address OnlyMergeAddress   `json:"address"`
+	Backup  *OnlyMergeAddress  `json:"backup"`
+	Others  []OnlyMergeAddress `json:"others"`
+}
+
+type OnlyMergeAddress struct {
+	City string  `json:"city"`
+	Zip  *string `json:"zip"`
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/reddyvinod/partialencode"
+)
+
+// The helpers of each of the only_*.go files are generated with no other helpers, see the
+// Makefile; these tests check that each of them works on its own.
+
+func TestOnlyCompose(t *testing.T) {
+	var a, b PartialOnlyComposeUser
+	a.Name, a.PartialValid.Name = "a", true
+	b.Backup, b.PartialValid.Backup = &PartialOnlyComposeAddress{City: "b"}, true
+	b.Backup.PartialValid.City = true
+
+	var v OnlyComposeUser
+	ApplyPartialOnlyComposeUser(&v, ComposePartialOnlyComposeUser(a, b))
+	if v.Name != "a" || v.Backup == nil || v.Backup.City != "b" {
+		t.Errorf("applied %+v; want both partials", v)
+	}
+}
+
+func TestOnlyInverse(t *testing.T) {
+	var p PartialOnlyInverseUser
+	p.Name, p.PartialValid.Name = "b", true
+
+	inv := InversePartialOnlyInverseUser(OnlyInverseUser{Name: "a"}, p)
+	if !inv.PartialValid.Name || inv.Name != "a" || inv.PartialValid.Address {
+		t.Errorf("InversePartialOnlyInverseUser() = %+v; want the original name", inv)
+	}
+}
+
+func TestOnlyChanges(t *testing.T) {
+	var p PartialOnlyChangesUser
+	if err := p.UnmarshalJSON([]byte(`{"name":"a","backup":{"city":"b"},"others":[{"city":"c"}]}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, c := range ChangesPartialOnlyChangesUser(OnlyChangesUser{Name: "a", Backup: &OnlyChangesAddress{}}, p) {
+		data, err := partialencode.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(data))
+	}
+	want := []string{
+		`{"path":"$.backup.city","op":"set","old":"","new":"b"}`,
+		`{"path":"$.others","op":"set","old":null,"new":[{"city":"c"}]}`,
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("ChangesPartialOnlyChangesUser() = %q; want %q", got, want)
+	}
+}
+
+func TestOnlyDeepCopy(t *testing.T) {
+	zip := "z"
+	v := OnlyDeepCopyUser{Backup: &OnlyDeepCopyAddress{Zip: &zip}}
+	c := v.DeepCopy()
+	*c.Backup.Zip = "y"
+	if *v.Backup.Zip != "z" || c.Equal(v) {
+		t.Errorf("the copy %+v shares %+v", c, v)
+	}
+}
+
+func TestOnlyTracked(t *testing.T) {
+	var v OnlyTrackedUser
+	tr := NewTrackedOnlyTrackedUser(&v)
+	tr.TrackAddress().SetCity("a")
+	if p := tr.Changes(); !p.PartialValid.Address || p.Address.City != "a" || p.PartialValid.Name {
+		t.Errorf("Changes() = %+v; want the city", p)
+	}
+}
+
+func TestOnlyMerge(t *testing.T) {
+	var a, b PartialOnlyMergeUser
+	a.Name, a.PartialValid.Name = "a", true
+	b.Name, b.PartialValid.Name = "b", true
+
+	p, cs := MergePartialOnlyMergeUser(OnlyMergeUser{}, a, b)
+	if len(cs) != 1 || cs[0].Path != "$.name" || p.Name != "a" {
+		t.Errorf("MergePartialOnlyMergeUser() = %+v, %+v; want a conflict of the names", p, cs)
+	}
+}
@@ -0,0 +1,13 @@
+package tests
+
+type OnlyTrackedUser struct {
+	Name    string               `json:"name"`
+	Address OnlyTrackedAddress   `json:"address"`
+	Backup  *OnlyTrackedAddress  `json:"backup"`
+	Others  []OnlyTrackedAddress `json:"others"`
+}
+
+type OnlyTrackedAddress struct {
+	City string  `json:"city"`
+	Zip  *string `json:"zip"`
+}
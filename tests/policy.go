@@ -0,0 +1,14 @@
+package tests
+
+type PolicyAccount struct {
+	Name    string         `json:"name"`
+	Role    string         `json:"role" partial:"write=admin"`
+	Plan    string         `json:"plan" partial:"write=admin|owner"`
+	Profile PolicyProfile  `json:"profile"`
+	Backup  *PolicyProfile `json:"backup"`
+}
+
+type PolicyProfile struct {
+	Bio      string `json:"bio"`
+	Verified bool   `json:"verified" partial:"write=admin"`
+}
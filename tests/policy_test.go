@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/reddyvinod/partialencode/jlexer"
+)
+
+const policyAccountJSON = `{"name":"a","role":"admin","plan":"pro","profile":{"bio":"b","verified":true},"backup":{"verified":true}}`
+
+// forbiddenPaths returns the paths of the errors of forbidden fields.
+func forbiddenPaths(t *testing.T, errs []*jlexer.LexerError) []string {
+	var paths []string
+	for _, e := range errs {
+		if e.Reason != jlexer.ReasonForbidden {
+			t.Errorf("error %v; want %q", e, jlexer.ReasonForbidden)
+		}
+		paths = append(paths, e.Path)
+	}
+	return paths
+}
+
+func checkPolicyFlags(t *testing.T, v PartialPolicyAccount, writable bool) {
+	t.Helper()
+	if !v.PartialValid.Name || !v.PartialValid.Plan || !v.Profile.PartialValid.Bio {
+		t.Errorf("flags %+v, %+v; want the writable fields", v.PartialValid, v.Profile.PartialValid)
+	}
+	if v.PartialValid.Role != writable || v.Profile.PartialValid.Verified != writable ||
+		v.Backup == nil || v.Backup.PartialValid.Verified != writable {
+		t.Errorf("flags of the fields of admins valid = %v, %v, %+v; want %v", v.PartialValid.Role, v.Profile.PartialValid.Verified, v.Backup, writable)
+	}
+	if !writable && (v.Role != "" || v.Profile.Verified || v.Backup.Verified) {
+		t.Errorf("forbidden fields are written: %+v", v)
+	}
+}
+
+func TestPolicyReject(t *testing.T) {
+	l := jlexer.Lexer{Data: []byte(policyAccountJSON), UseMultipleErrors: true}
+	l.Policy = &jlexer.WritePolicy{Roles: []string{"owner"}}
+	var v PartialPolicyAccount
+	v.UnMarshalPartialJSON(&l)
+	if err := l.Error(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"$.role", "$.profile.verified", "$.backup.verified"}
+	if got := forbiddenPaths(t, l.GetNonFatalErrors()); !reflect.DeepEqual(got, want) {
+		t.Errorf("errors at %q; want %q", got, want)
+	}
+	checkPolicyFlags(t, v, false)
+
+	// Without multiple errors the first forbidden field fails the decoding.
+	l = jlexer.Lexer{Data: []byte(policyAccountJSON), Policy: l.Policy}
+	v = PartialPolicyAccount{}
+	v.UnMarshalPartialJSON(&l)
+	if e, ok := l.Error().(*jlexer.LexerError); !ok || e.Reason != jlexer.ReasonForbidden || e.Path != "$.role" {
+		t.Errorf("error = %v; want %q at $.role", l.Error(), jlexer.ReasonForbidden)
+	}
+}
+
+func TestPolicyDrop(t *testing.T) {
+	l := jlexer.Lexer{Data: []byte(policyAccountJSON)}
+	l.Policy = &jlexer.WritePolicy{Roles: []string{"owner"}, Drop: true}
+	var v PartialPolicyAccount
+	v.UnMarshalPartialJSON(&l)
+	if err := l.Error(); err != nil {
+		t.Fatal(err)
+	}
+	checkPolicyFlags(t, v, false)
+}
+
+func TestPolicyAllow(t *testing.T) {
+	for _, policy := range []*jlexer.WritePolicy{nil, {Roles: []string{"admin"}}} {
+		l := jlexer.Lexer{Data: []byte(policyAccountJSON), Policy: policy}
+		var v PartialPolicyAccount
+		v.UnMarshalPartialJSON(&l)
+		if err := l.Error(); err != nil {
+			t.Fatal(err)
+		}
+		checkPolicyFlags(t, v, true)
+	}
+}
+
+func TestPolicyLazy(t *testing.T) {
+	l := jlexer.Lexer{Data: []byte(policyAccountJSON), UseMultipleErrors: true}
+	l.Policy = &jlexer.WritePolicy{Roles: []string{"owner"}}
+	var v LazyPartialPolicyAccount
+	v.UnMarshalPartialJSON(&l)
+	if err := l.Error(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nested values are decoded eagerly under a policy, so that their fields are authorized.
+	want := []string{"$.role", "$.profile.verified", "$.backup.verified"}
+	if got := forbiddenPaths(t, l.GetNonFatalErrors()); !reflect.DeepEqual(got, want) {
+		t.Errorf("errors at %q; want %q", got, want)
+	}
+	p, err := v.Partial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPolicyFlags(t, p, false)
+}
@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/reddyvinod/partialencode/jwriter"
+)
+
+func TestRedactSensitive(t *testing.T) {
+	var p PartialComposeUser
+	if err := p.UnmarshalJSON([]byte(`{"name":"a","password":"hunter2"}`)); err != nil {
+		t.Fatal(err)
+	}
+	hashed := func(password string) string {
+		var q PartialComposeUser
+		if err := q.UnmarshalJSON([]byte(`{"password":"` + password + `"}`)); err != nil {
+			t.Fatal(err)
+		}
+		w := jwriter.Writer{RedactSensitive: jwriter.RedactHash, HashKey: []byte("key")}
+		q.MarshalPartialJSON(&w)
+		return string(w.Buffer.BuildBytes())
+	}
+
+	for _, test := range []struct {
+		Writer jwriter.Writer
+		Want   string
+	}{
+		{jwriter.Writer{}, `{"name":"a","password":"hunter2"}`},
+		{jwriter.Writer{RedactSensitive: jwriter.RedactPlaceholder}, `{"name":"a","password":"***"}`},
+		{jwriter.Writer{RedactSensitive: jwriter.RedactPlaceholder, Placeholder: "-"}, `{"name":"a","password":"-"}`},
+		{jwriter.Writer{RedactSensitive: jwriter.RedactOmit}, `{"name":"a"}`},
+		// HMAC-SHA256 of "hunter2", quoted as in JSON, keyed with "key".
+		{jwriter.Writer{RedactSensitive: jwriter.RedactHash, HashKey: []byte("key")},
+			`{"name":"a","password":"0faa58a462bb22f53b69b6941aa3b9757d8e1be48733e50d3d95df2165cde5ed"}`},
+	} {
+		w := test.Writer
+		p.MarshalPartialJSON(&w)
+		if got := string(w.Buffer.BuildBytes()); got != test.Want {
+			t.Errorf("RedactSensitive %v: got %s; want %s", test.Writer.RedactSensitive, got, test.Want)
+		}
+	}
+
+	if hashed("hunter2") == hashed("hunter3") {
+		t.Errorf("hashes of different values are equal")
+	}
+}
@@ -1,8 +1,9 @@
 package tests
 
 import (
-	"fmt"
 	"testing"
+
+	"github.com/reddyvinod/partialencode/jlexer"
 )
 
 func TestRequiredField(t *testing.T) {
@@ -20,8 +21,9 @@ func TestRequiredField(t *testing.T) {
 				t.Errorf("%s. UnmarshalJSON didn`t expect error: %v", tc.json, err)
 			}
 		} else {
-			if fmt.Sprintf("%v", err) != tc.errorMessage {
-				t.Errorf("%s. UnmarshalJSON expected error: %v. got: %v", tc.json, tc.errorMessage, err)
+			lerr, ok := err.(*jlexer.LexerError)
+			if !ok || lerr.Reason != tc.errorMessage || lerr.Path != "$.first_name" {
+				t.Errorf("%s. UnmarshalJSON expected error: %v at $.first_name. got: %v", tc.json, tc.errorMessage, err)
 			}
 		}
 	}
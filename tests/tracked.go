@@ -0,0 +1,12 @@
+package tests
+
+type TrackingAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type TrackingUser struct {
+	Name    string           `json:"name"`
+	Address TrackingAddress  `json:"address"`
+	Billing *TrackingAddress `json:"billing"`
+}
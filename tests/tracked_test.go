@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"testing"
+)
+
+func TestTrackedSetters(t *testing.T) {
+	v := TrackingUser{Name: "a", Address: TrackingAddress{City: "x", Zip: "1"}}
+	tr := NewTrackedTrackingUser(&v)
+	if tr.Changed() {
+		t.Errorf("Changed() = true before any write")
+	}
+
+	tr.SetName("b")
+	tr.TrackAddress().SetZip("2")
+	if !tr.Changed() || v.Name != "b" || v.Address.Zip != "2" {
+		t.Fatalf("value = %+v; want the writes applied", v)
+	}
+
+	p := tr.Changes()
+	if !p.PartialValid.Name || p.Name != "b" {
+		t.Errorf("Changes().Name = %q, valid %v; want b", p.Name, p.PartialValid.Name)
+	}
+	if !p.PartialValid.Address || !p.Address.PartialValid.Zip || p.Address.PartialValid.City {
+		t.Errorf("Changes().Address = %+v; want only the zip", p.Address)
+	}
+	if p.PartialValid.Billing {
+		t.Errorf("Changes().Billing is valid; want it unchanged")
+	}
+
+	tr.Commit()
+	if tr.Changed() || tr.Changes().PartialValid.Address {
+		t.Errorf("Changed() = true after Commit")
+	}
+}
+
+func TestTrackedNilPointer(t *testing.T) {
+	var v TrackingUser
+	tr := NewTrackedTrackingUser(&v)
+
+	// Tracking a nil pointer allocates it, which changes the whole field.
+	tr.TrackBilling().SetCity("x")
+	if v.Billing == nil || v.Billing.City != "x" {
+		t.Fatalf("Billing = %+v; want the city written", v.Billing)
+	}
+	p := tr.Changes()
+	if !p.PartialValid.Billing || p.Billing == nil || p.Billing.City != "x" || !p.Billing.PartialValid.Zip {
+		t.Errorf("Changes().Billing = %+v; want the whole address", p.Billing)
+	}
+
+	tr.Commit()
+	tr.TrackBilling().SetZip("2")
+	p = tr.Changes()
+	if !p.PartialValid.Billing || p.Billing == nil || p.Billing.PartialValid.City || !p.Billing.PartialValid.Zip {
+		t.Errorf("Changes().Billing = %+v; want only the zip", p.Billing)
+	}
+}
@@ -0,0 +1,26 @@
+package tests
+
+type UnionShape interface {
+	Area() float64
+}
+
+//partialencode:variant UnionShape type=circle
+type UnionCircle struct {
+	R float64 `json:"r"`
+}
+
+func (c *UnionCircle) Area() float64 { return 3 * c.R * c.R }
+
+//partialencode:variant UnionShape type=square
+type UnionSquare struct {
+	Side  float64 `json:"side"`
+	Color string  `json:"color"`
+}
+
+func (s *UnionSquare) Area() float64 { return s.Side * s.Side }
+
+type UnionDrawing struct {
+	Name   string       `json:"name"`
+	Main   UnionShape   `json:"main" partial:"union=type"`
+	Others []UnionShape `json:"others"`
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/reddyvinod/partialencode/jlexer"
+)
+
+func TestUnionDispatch(t *testing.T) {
+	in := `{"name":"d","main":{"side":2,"color":"red","type":"square"},"others":[{"type":"circle","r":1},null]}`
+
+	var v PartialUnionDrawing
+	if err := v.UnmarshalJSON([]byte(in)); err != nil {
+		t.Fatal(err)
+	}
+	if sq, ok := v.Main.(*PartialUnionSquare); !ok || sq.Side != 2 || sq.Color != "red" {
+		t.Errorf("Main = %#v; want the square", v.Main)
+	}
+	if len(v.Others) != 2 || v.Others[1] != nil {
+		t.Fatalf("Others = %#v; want a circle and null", v.Others)
+	}
+	if c, ok := v.Others[0].(*PartialUnionCircle); !ok || c.R != 1 {
+		t.Errorf("Others[0] = %#v; want the circle", v.Others[0])
+	}
+
+	// The discriminator is written first.
+	out, err := v.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"d","main":{"type":"square","side":2,"color":"red"},"others":[{"type":"circle","r":1},null]}`
+	if string(out) != want {
+		t.Errorf("MarshalJSON() = %s; want %s", out, want)
+	}
+}
+
+func TestUnionPatch(t *testing.T) {
+	var v PartialUnionDrawing
+	if err := v.UnmarshalJSON([]byte(`{"main":{"type":"square","side":2,"color":"red"}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// A value without the discriminator patches the current variant.
+	if err := v.UnmarshalJSON([]byte(`{"main":{"color":"blue"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if sq, ok := v.Main.(*PartialUnionSquare); !ok || sq.Side != 2 || sq.Color != "blue" {
+		t.Errorf("Main = %#v; want the patched square", v.Main)
+	}
+
+	// A different discriminator replaces it.
+	if err := v.UnmarshalJSON([]byte(`{"main":{"r":3,"type":"circle"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := v.Main.(*PartialUnionCircle); !ok || c.R != 3 {
+		t.Errorf("Main = %#v; want the circle", v.Main)
+	}
+
+	var n PartialUnionDrawing
+	if err := n.UnmarshalJSON([]byte(`{"main":null}`)); err != nil {
+		t.Fatal(err)
+	}
+	if !n.PartialSet.Main || n.PartialValid.Main || n.Main != nil {
+		t.Errorf("Main = %#v; want null", n.Main)
+	}
+}
+
+func TestUnionErrors(t *testing.T) {
+	for i, test := range []struct {
+		In, Reason string
+	}{
+		{In: `{"main":{"r":1}}`, Reason: "missing discriminator of PartialUnionShape"},
+		{In: `{"main":{"type":"hexagon"}}`, Reason: "unknown variant of PartialUnionShape"},
+		{In: `{"others":[{"side":1}]}`, Reason: "missing discriminator of PartialUnionShape"},
+	} {
+		var v PartialUnionDrawing
+		err := v.UnmarshalJSON([]byte(test.In))
+		if e, ok := err.(*jlexer.LexerError); !ok || e.Reason != test.Reason {
+			t.Errorf("[%d] UnmarshalJSON(%s) error = %v; want %q", i, test.In, err, test.Reason)
+		}
+	}
+}
+
+func TestUnionStream(t *testing.T) {
+	in := `{"name":"d","main":{"side":2,"color":"` + strings.Repeat("r", 100) + `","type":"square"}}`
+
+	l := jlexer.Lexer{Reader: iotest.OneByteReader(strings.NewReader(in))}
+	var v PartialUnionDrawing
+	v.UnMarshalPartialJSON(&l)
+	if err := l.Error(); err != nil {
+		t.Fatal(err)
+	}
+	if sq, ok := v.Main.(*PartialUnionSquare); !ok || sq.Side != 2 || len(sq.Color) != 100 {
+		t.Errorf("Main = %#v; want the square", v.Main)
+	}
+}
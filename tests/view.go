@@ -0,0 +1,14 @@
+package tests
+
+type ViewAccount struct {
+	ID      int         `json:"id"`
+	Email   string      `json:"email" partial:"view=internal,admin"`
+	Notes   string      `json:"notes" partial:"view=admin"`
+	Owner   ViewOwner   `json:"owner"`
+	Members []ViewOwner `json:"members" partial:"view=internal,admin"`
+}
+
+type ViewOwner struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone" partial:"view=admin"`
+}
@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/reddyvinod/partialencode/jwriter"
+)
+
+const viewAccountJSON = `{"id": 1, "email": "a@b", "notes": "n", "owner": {"name": "o", "phone": "1"}, "members": [{"name": "m", "phone": "2"}]}`
+
+var viewTests = []struct {
+	View, Want string
+}{
+	{"", `{"id":1,"email":"a@b","notes":"n","owner":{"name":"o","phone":"1"},"members":[{"name":"m","phone":"2"}]}`},
+	{"public", `{"id":1,"owner":{"name":"o"}}`},
+	{"internal", `{"id":1,"email":"a@b","owner":{"name":"o"},"members":[{"name":"m"}]}`},
+	{"admin", `{"id":1,"email":"a@b","notes":"n","owner":{"name":"o","phone":"1"},"members":[{"name":"m","phone":"2"}]}`},
+}
+
+func TestViews(t *testing.T) {
+	var v PartialViewAccount
+	if err := v.UnmarshalJSON([]byte(viewAccountJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range viewTests {
+		w := jwriter.Writer{View: test.View}
+		v.MarshalPartialJSON(&w)
+		got, err := w.BuildBytes()
+		if err != nil {
+			t.Errorf("view %q: %v", test.View, err)
+		} else if string(got) != test.Want {
+			t.Errorf("view %q: got %s; want %s", test.View, got, test.Want)
+		}
+	}
+}
+
+func TestViewsLazy(t *testing.T) {
+	// Raw values of nested structs are decoded to filter their fields, unless all fields are
+	// written.
+	for _, test := range viewTests {
+		var v LazyPartialViewAccount
+		if err := v.UnmarshalJSON([]byte(viewAccountJSON)); err != nil {
+			t.Fatal(err)
+		}
+
+		w := jwriter.Writer{View: test.View}
+		v.MarshalPartialJSON(&w)
+		got, err := w.BuildBytes()
+		want := test.Want
+		if test.View == "" {
+			want = `{"id":1,"email":"a@b","notes":"n","owner":{"name": "o", "phone": "1"},"members":[{"name": "m", "phone": "2"}]}`
+		}
+		if err != nil {
+			t.Errorf("view %q: %v", test.View, err)
+		} else if string(got) != want {
+			t.Errorf("view %q: got %s; want %s", test.View, got, want)
+		}
+	}
+}